@@ -0,0 +1,155 @@
+package zaplogmanager
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// 压缩归档读取模块：补上只有写侧（gzip/zstd/lz4/xz 压缩）却没有读侧的缺口，
+// 避免下游每次都要重新实现一遍归档扫描（大文件逐行扫描是真实存在的痛点）
+
+const defaultScannerMaxLineBytes = 10 << 20 // 10MiB，单行日志超出此长度视为异常数据
+
+// ReadCompressed 逐行扫描一个归档文件（根据扩展名自动识别 gzip/zstd/lz4/xz，无法识别则按原始文本读取），
+// 对每一行调用 handler；handler 返回非 nil 错误会立即中止扫描并向上返回
+func ReadCompressed(path string, handler func(line []byte) error) error {
+	return ReadCompressedWithBuffer(path, defaultScannerMaxLineBytes, handler)
+}
+
+// ReadCompressedWithBuffer 是 ReadCompressed 的可配置版本，用于单行可能超过默认10MiB的场景
+func ReadCompressedWithBuffer(path string, maxLineBytes int, handler func(line []byte) error) error {
+	rc, err := openDecompressedReader(path)
+	if err != nil {
+		return fmt.Errorf("打开归档文件失败: %w", err)
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	for scanner.Scan() {
+		if err := handler(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// decompressedFile 包装底层文件与解压器，统一 Close 语义
+type decompressedFile struct {
+	io.Reader
+	f  *os.File
+	gz *gzip.Reader
+	zr *zstd.Decoder
+}
+
+func (d *decompressedFile) Close() error {
+	if d.gz != nil {
+		d.gz.Close()
+	}
+	if d.zr != nil {
+		d.zr.Close()
+	}
+	return d.f.Close()
+}
+
+// openDecompressedReader 根据文件扩展名选择解压器，未知扩展名按原始文本读取
+func openDecompressedReader(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("打开gzip归档失败: %w", err)
+		}
+		return &decompressedFile{Reader: gz, f: f, gz: gz}, nil
+
+	case strings.HasSuffix(path, ".zst"):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("打开zstd归档失败: %w", err)
+		}
+		return &decompressedFile{Reader: zr, f: f, zr: zr}, nil
+
+	case strings.HasSuffix(path, ".lz4"):
+		return &decompressedFile{Reader: lz4.NewReader(f), f: f}, nil
+
+	case strings.HasSuffix(path, ".xz"):
+		xr, err := xz.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("打开xz归档失败: %w", err)
+		}
+		return &decompressedFile{Reader: xr, f: f}, nil
+
+	default:
+		return f, nil
+	}
+}
+
+// AggregateByWindow 并发扫描多个归档文件，按 window 粒度（如5分钟）聚合 extract 提取出的 key/value，
+// 每个文件一个goroutine，但并发数受限于 runtime.GOMAXPROCS(0)；extract 返回 ok=false 时该行被忽略。
+// 个别归档损坏/截断不应抹掉其余成百上千个文件的聚合结果：始终返回已成功文件的完整聚合结果，
+// 同时通过 errors.Join 把所有失败文件的错误一起返回，调用方可按需决定是否视为致命错误
+func AggregateByWindow(paths []string, window time.Duration, extract func(line []byte) (ts time.Time, value int64, key string, ok bool)) (map[string]map[int64]int64, error) {
+	windowSecs := int64(window.Seconds())
+	if windowSecs <= 0 {
+		return nil, fmt.Errorf("window 过小（不足1秒）: %v", window)
+	}
+
+	acc := newShardedAccumulator()
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	errCh := make(chan error, len(paths))
+
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		path := path
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := ReadCompressed(path, func(line []byte) error {
+				ts, value, key, ok := extract(line)
+				if !ok {
+					return nil
+				}
+				bucket := ts.Unix() - ts.Unix()%windowSecs
+				acc.add(key, bucket, value)
+				return nil
+			})
+			if err != nil {
+				errCh <- fmt.Errorf("聚合归档文件失败 %s: %w", path, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+
+	return acc.merge(), errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package zaplogmanager
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,44 +25,68 @@ var (
 // 参数说明：hour(小时) minute(分钟) second(秒) - 每天运行时间
 // compressMaxSave: 压缩文件保留时间 logDirs: 需要监控的日志目录
 func StartLogCompression(hour, minute, second int, compressMaxSave time.Duration, logDirs ...string) {
+	StartLogCompressionWithOptions(hour, minute, second, compressMaxSave, logDirs)
+}
+
+// StartLogCompressionWithOptions 是 StartLogCompression 的可配置版本，支持通过 WithRetentionPolicy
+// 等函数式选项叠加 lumberjack 风格的 MaxBackups/MaxAge/MaxSize/MinFreeDisk 保留策略。
+// 内部使用 Scheduler 注册 overnight-compression/size-monitor 两个命名任务，
+// 取代原先 scheduleDailyJob 硬编码的单一每日定时器（其 isTargetHour(next, 1) 只有调度到凌晨1点才会真正执行）
+func StartLogCompressionWithOptions(hour, minute, second int, compressMaxSave time.Duration, logDirs []string, opts ...Option) {
+	o := defaultLoggerOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.retention != nil {
+		setRetentionPolicy(o.retention)
+	}
+
 	zap.S().Debugf("开始启动首次日志压缩和清理...")
 	safeRunCompressionJob(logDirs, compressMaxSave)
 
-	// 启动定时任务
-	go scheduleDailyJob(hour, minute, second, compressMaxSave, logDirs...)
+	// 启用 WithFsnotifyWatch 时，用事件驱动的 Manager 取代轮询式的 size-monitor 任务；
+	// 失败则记录错误并回退到轮询，保证该选项不会导致压缩完全停摆
+	fsnotifyActive := false
+	if o.fsnotifyWatch {
+		mgr := NewManager(compressMaxSave, logDirs...)
+		if err := mgr.Start(context.Background()); err != nil {
+			zap.S().Errorf("启动fsnotify监听失败，回退到轮询式size-monitor任务: %v", err)
+		} else {
+			defaultManager = mgr
+			fsnotifyActive = true
+			zap.S().Infof("已启用fsnotify事件驱动压缩，跳过轮询式size-monitor任务")
+		}
+	}
 
-	// 启动一个独立goroutine来执行定时监控任务
-	// 启动大小监控任务
-	go func() {
-		ticker := time.NewTicker(sizeCheckInterval)
-		defer ticker.Stop()
+	scheduler := NewScheduler()
 
-		for range ticker.C {
-			zap.S().Debugf("启动小时级日志大小监控...")
-			safeRunCompressionJob(logDirs, compressMaxSave)
-		}
-	}()
-}
+	overnightExpr := fmt.Sprintf("%d %d %d * * *", second, minute, hour)
+	if err := scheduler.AddJob("overnight-compression", overnightExpr, func(ctx context.Context) {
+		safeRunCompressionJob(logDirs, compressMaxSave)
 
-// scheduleDailyJob 核心调度逻辑
-func scheduleDailyJob(hour, minute, second int, compressMaxSave time.Duration, logDirs ...string) {
-	for {
-		next := nextRunTime(hour, minute, second)
-		timer := time.NewTimer(time.Until(next))
-		<-timer.C
+		fileLock.Lock()
+		processOvernightLogs(logDirs, compressMaxSave)
+		fileLock.Unlock()
+	}); err != nil {
+		zap.S().Errorf("注册跨天压缩任务失败: %v", err)
+	}
 
-		if isTargetHour(next, 1) {
-			// 执行压缩任务
+	if !fsnotifyActive {
+		sizeMonitorExpr := fmt.Sprintf("@every %s", sizeCheckInterval)
+		if err := scheduler.AddJob("size-monitor", sizeMonitorExpr, func(ctx context.Context) {
+			zap.S().Debugf("启动小时级日志大小监控...")
 			safeRunCompressionJob(logDirs, compressMaxSave)
 
-			// 执行跨天压缩
 			fileLock.Lock()
-			processOvernightLogs(logDirs, compressMaxSave)
+			enforceRetentionAll(logDirs)
 			fileLock.Unlock()
+		}); err != nil {
+			zap.S().Errorf("注册大小监控任务失败: %v", err)
 		}
-
-		timer.Stop()
 	}
+
+	scheduler.Start()
+	defaultScheduler = scheduler
 }
 
 func processOvernightLogs(logDirs []string, compressMaxSave time.Duration) {
@@ -87,25 +112,40 @@ func processOvernightLogs(logDirs []string, compressMaxSave time.Duration) {
 		if err := cleanExpiredGzLogs(dir, compressMaxSave); err != nil {
 			zap.S().Errorf("清理过期日志失败：%v", err)
 		}
+
+		// 清理已使用 With<Level>BucketPolicy 分桶布局、且整桶都已过期的时间分桶目录
+		if err := cleanExpiredBucketDirs(dir, compressMaxSave); err != nil {
+			zap.S().Errorf("清理过期分桶目录失败：%v", err)
+		}
 	}
-}
 
-// isTargetHour 判断是否是目标小时
-func isTargetHour(t time.Time, targetHour int) bool {
-	return t.Hour() == targetHour
+	// 在daily job既有的fileLock保护下，叠加执行 MaxBackups/MaxAge/MaxSize/MinFreeDisk 保留策略
+	enforceRetentionAll(logDirs)
 }
 
-// forceCompressOvernightLog 跨天压缩
+// forceCompressOvernightLog 跨天压缩，压缩扩展名取决于当前激活的 Compressor；
+// 超过 WithParallelCompression 配置阈值的大文件会切分为多分片并发压缩
 func forceCompressOvernightLog(src string) error {
-	// 保持原始文件名格式，只添加序号和.gz后缀
+	// 保持原始文件名格式，只添加序号和压缩扩展名
 	baseName := src
-	compressedName := fmt.Sprintf("%s.1.gz", baseName)
+	ext := CurrentCompressor().Extension()
+	compressedName := fmt.Sprintf("%s.1%s", baseName, ext)
 
 	// 检查是否已存在压缩文件
 	if _, err := os.Stat(compressedName); err == nil {
 		return compressCurrentLogWithIndex(src)
 	}
 
+	if fi, err := os.Stat(src); err == nil {
+		if workers, ok := shouldUseParallelCompression(fi.Size()); ok {
+			zap.S().Infof("大文件跨天压缩启用并行模式(%d路): %s", workers, src)
+			if err := compressFileParallel(src, compressedName, workers); err != nil {
+				return fmt.Errorf("跨天并行压缩失败: %w", err)
+			}
+			return os.Remove(src)
+		}
+	}
+
 	// 执行压缩
 	if err := gzipLogFileWithIndex(src, compressedName); err != nil {
 		return fmt.Errorf("跨天压缩失败: %w", err)
@@ -117,24 +157,5 @@ func forceCompressOvernightLog(src string) error {
 
 func isYesterdayLog(path string, yesterday string) bool {
 	baseName := filepath.Base(path)
-	return strings.Contains(baseName, yesterday) && !gzExtRegex.MatchString(baseName)
-}
-
-// 计算下一个执行时刻（精确到秒）
-func nextRunTime(targetHour, targetMin, targetSec int) time.Time {
-	now := time.Now()
-
-	// 构造目标时间
-	next := time.Date(
-		now.Year(), now.Month(), now.Day(),
-		targetHour, targetMin, targetSec, 0,
-		now.Location(),
-	)
-
-	// 如果今天的时间已过，则设置为明天
-	if next.Before(now) {
-		next = next.Add(24 * time.Hour)
-	}
-
-	return next
+	return strings.Contains(baseName, yesterday) && !CurrentCompressor().Match(baseName)
 }
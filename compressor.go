@@ -0,0 +1,198 @@
+package zaplogmanager
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+	"go.uber.org/zap"
+)
+
+// 可插拔压缩器模块：将压缩管线从硬编码的 compress/gzip 解耦，
+// 支持按需切换为 zstd/lz4，cleanExpiredGzLogs/existingIndex 等按当前压缩器的扩展名动态匹配
+
+// Compressor 描述一种压缩算法
+type Compressor interface {
+	// Extension 返回该压缩器产生的文件扩展名，如 ".gz"、".zst"、".lz4"
+	Extension() string
+	// NewWriter 包装底层 io.Writer，返回压缩写入器
+	NewWriter(w io.Writer) io.WriteCloser
+	// Match 判断路径是否属于该压缩器产生的压缩文件
+	Match(path string) bool
+}
+
+// GzipCompressor 基于 compress/gzip 的默认压缩器，Lvl 为0时使用 gzip.DefaultCompression
+type GzipCompressor struct {
+	Lvl int
+}
+
+func (GzipCompressor) Extension() string { return ".gz" }
+func (c GzipCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	level := c.Lvl
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		zap.S().Panicf("创建gzip写入器失败: %v", err)
+	}
+	return gw
+}
+func (GzipCompressor) Match(path string) bool { return strings.HasSuffix(path, ".gz") }
+func (c GzipCompressor) Level() int            { return c.Lvl }
+
+// SupportsConcatenation: gzip.Reader 默认按 multistream 模式透明解码前后拼接的多个成员，见 compress/gzip 文档
+func (GzipCompressor) SupportsConcatenation() bool { return true }
+
+// ZstdCompressor 基于 github.com/klauspost/compress/zstd 的压缩器，压缩速度显著快于gzip
+type ZstdCompressor struct {
+	Lvl zstd.EncoderLevel
+}
+
+func (ZstdCompressor) Extension() string { return ".zst" }
+func (c ZstdCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	level := c.Lvl
+	if level == 0 {
+		level = zstd.SpeedDefault
+	}
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(level))
+	if err != nil {
+		// NewWriter 仅在参数非法时才会出错，发生意味着压缩器构造有误，直接panic暴露问题
+		zap.S().Panicf("创建zstd写入器失败: %v", err)
+	}
+	return enc
+}
+func (ZstdCompressor) Match(path string) bool { return strings.HasSuffix(path, ".zst") }
+func (c ZstdCompressor) Level() int           { return int(c.Lvl) }
+
+// SupportsConcatenation: zstd.Decoder 在流式读取时会连续解码拼接在一起的多个frame，行为透明
+func (ZstdCompressor) SupportsConcatenation() bool { return true }
+
+// Lz4Compressor 基于 github.com/pierrec/lz4/v4 的压缩器
+type Lz4Compressor struct{}
+
+func (Lz4Compressor) Extension() string { return ".lz4" }
+func (Lz4Compressor) NewWriter(w io.Writer) io.WriteCloser {
+	return lz4.NewWriter(w)
+}
+func (Lz4Compressor) Match(path string) bool { return strings.HasSuffix(path, ".lz4") }
+
+// SupportsConcatenation: archive_reader.go 对 lz4 只用 lz4.NewReader 打开单个非循环的frame读取器，
+// 未验证其对拼接多frame的行为，保守起见不允许并行分片压缩，避免读侧静默丢数据
+func (Lz4Compressor) SupportsConcatenation() bool { return false }
+
+// XzCompressor 基于 github.com/ulikunitz/xz 的压缩器，压缩比通常优于gzip，但速度较慢
+type XzCompressor struct{}
+
+func (XzCompressor) Extension() string { return ".xz" }
+func (XzCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	xw, err := xz.NewWriter(w)
+	if err != nil {
+		// 同 zstd.NewWriter，仅在参数非法时出错，属于压缩器构造错误
+		zap.S().Panicf("创建xz写入器失败: %v", err)
+	}
+	return xw
+}
+func (XzCompressor) Match(path string) bool { return strings.HasSuffix(path, ".xz") }
+
+// SupportsConcatenation: 同 Lz4Compressor，xz.NewReader 同样只读取单个stream，未验证多stream拼接行为，
+// 保守起见不允许并行分片压缩
+func (XzCompressor) SupportsConcatenation() bool { return false }
+
+// LeveledCompressor 是可选接口，供支持压缩级别的 Compressor 实现（gzip/zstd）声明其当前级别
+type LeveledCompressor interface {
+	Compressor
+	Level() int
+}
+
+// ConcatenableCompressor 是可选接口，声明该压缩器产出的多个压缩流前后拼接后能否被透明地连续解码。
+// compressFileParallel 依赖这一点把大文件拆成多分片并发压缩、再顺序拼接写入同一个目标文件；
+// 不满足该性质的压缩器绝不能走并行分片路径，否则 ReadCompressed 只会读出第一个分片就提前结束，静默丢数据
+type ConcatenableCompressor interface {
+	Compressor
+	SupportsConcatenation() bool
+}
+
+// supportsParallelCompression 判断压缩器是否声明支持拼接多个流；未实现 ConcatenableCompressor 的一律视为不支持
+func supportsParallelCompression(c Compressor) bool {
+	cc, ok := c.(ConcatenableCompressor)
+	return ok && cc.SupportsConcatenation()
+}
+
+var (
+	compressorMu     sync.RWMutex
+	activeCompressor Compressor = GzipCompressor{}
+)
+
+// SetCompressor 切换全局使用的压缩器，影响后续的压缩/清理/序号解析逻辑
+func SetCompressor(c Compressor) {
+	compressorMu.Lock()
+	defer compressorMu.Unlock()
+	activeCompressor = c
+}
+
+// CurrentCompressor 返回当前生效的压缩器
+func CurrentCompressor() Compressor {
+	compressorMu.RLock()
+	defer compressorMu.RUnlock()
+	return activeCompressor
+}
+
+// WithCompressor 是 InitLoggerWithOptions 的函数式选项，用于选择压缩算法
+func WithCompressor(c Compressor) Option {
+	return func(o *loggerOptions) { o.compressor = c }
+}
+
+// compressFileWithActive 使用当前压缩器压缩 src，产物路径为 src + 当前压缩器扩展名
+func compressFileWithActive(src string) (string, error) {
+	compressor := CurrentCompressor()
+	dst := src + compressor.Extension()
+
+	inFile, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("打开待压缩文件失败: %w", err)
+	}
+	defer inFile.Close()
+
+	outFile, err := os.Create(dst)
+	if err != nil {
+		return "", fmt.Errorf("创建压缩文件失败: %w", err)
+	}
+	defer func() {
+		if err := outFile.Close(); err != nil {
+			zap.S().Error(err)
+		}
+	}()
+
+	writer := compressor.NewWriter(outFile)
+	defer func() {
+		if err := writer.Close(); err != nil {
+			zap.S().Error(err)
+		}
+	}()
+
+	if _, err := io.Copy(writer, inFile); err != nil {
+		return "", fmt.Errorf("压缩写入失败: %w", err)
+	}
+
+	return dst, nil
+}
+
+// compressedExtRegex 构造一个匹配"当前压缩器扩展名"日志文件的正则，供清理/序号扫描复用
+func compressedExtRegex() *regexp.Regexp {
+	ext := regexp.QuoteMeta(CurrentCompressor().Extension())
+	return regexp.MustCompile(`\.log` + ext + `$`)
+}
+
+// existingIndexRegex 构造形如 \.log\.(\d+)\.<ext>$ 的正则，用于从已压缩文件名解析序号
+func existingIndexRegex() *regexp.Regexp {
+	ext := regexp.QuoteMeta(CurrentCompressor().Extension())
+	return regexp.MustCompile(`\.log\.(\d+)` + ext + `$`)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package zaplogmanager
+
+import "syscall"
+
+// diskFreeBytes 返回 path 所在文件系统的可用字节数（syscall.Statfs）
+func diskFreeBytes(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
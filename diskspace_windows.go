@@ -0,0 +1,20 @@
+//go:build windows
+
+package zaplogmanager
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// diskFreeBytes 返回 path 所在磁盘卷的可用字节数（GetDiskFreeSpaceEx）
+func diskFreeBytes(path string) (int64, error) {
+	var freeBytesAvailable uint64
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytesAvailable, nil, nil); err != nil {
+		return 0, err
+	}
+	return int64(freeBytesAvailable), nil
+}
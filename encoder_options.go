@@ -0,0 +1,192 @@
+package zaplogmanager
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 编码器与结构化字段配置模块：在 GetConfig 固定使用 console 编码器的基础上，
+// 支持 JSON 编码器以及按核心（文件/控制台）独立选择编码格式，满足日志采集到ELK/Loki/SLS等场景
+
+// EncoderKind 日志编码格式
+type EncoderKind int
+
+const (
+	// EncoderConsole 人类可读的控制台格式（默认，与 GetConfig 行为一致）
+	EncoderConsole EncoderKind = iota
+	// EncoderJSON 结构化 JSON 格式，适合被日志采集系统解析
+	EncoderJSON
+)
+
+// Option 用于配置 InitLoggerWithOptions 的函数式选项
+type Option func(*loggerOptions)
+
+type loggerOptions struct {
+	fileEncoderKind     EncoderKind
+	consoleEncoderKind  EncoderKind
+	timeEncoder         zapcore.TimeEncoder
+	levelEncoder        zapcore.LevelEncoder
+	callerEncoder       zapcore.CallerEncoder
+	stacktraceKey       string
+	staticFields        []zap.Field
+	compressor          Compressor
+	parallelWorkers     int
+	parallelThreshold   int64
+	retention           *RetentionPolicy
+	syslogRoute         RouteFunc
+	syslogBaseDir       string
+	syslogRolloverBytes int64
+	fsnotifyWatch       bool
+	warnBucket          *BucketPolicy
+	infoBucket          *BucketPolicy
+	debugBucket         *BucketPolicy
+}
+
+func defaultLoggerOptions() loggerOptions {
+	return loggerOptions{
+		fileEncoderKind:    EncoderConsole,
+		consoleEncoderKind: EncoderConsole,
+		timeEncoder:        zapcore.ISO8601TimeEncoder,
+		levelEncoder:       zapcore.CapitalLevelEncoder,
+		stacktraceKey:      "stacktrace",
+	}
+}
+
+// WithFileEncoder 设置写入文件的核心所使用的编码格式
+func WithFileEncoder(kind EncoderKind) Option {
+	return func(o *loggerOptions) { o.fileEncoderKind = kind }
+}
+
+// WithConsoleEncoder 设置控制台核心所使用的编码格式
+func WithConsoleEncoder(kind EncoderKind) Option {
+	return func(o *loggerOptions) { o.consoleEncoderKind = kind }
+}
+
+// WithTimeEncoder 覆盖默认的时间编码器（默认 zapcore.ISO8601TimeEncoder）
+func WithTimeEncoder(enc zapcore.TimeEncoder) Option {
+	return func(o *loggerOptions) { o.timeEncoder = enc }
+}
+
+// WithLevelEncoder 覆盖默认的级别编码器（默认 zapcore.CapitalLevelEncoder）
+func WithLevelEncoder(enc zapcore.LevelEncoder) Option {
+	return func(o *loggerOptions) { o.levelEncoder = enc }
+}
+
+// WithCallerEncoder 覆盖默认的调用位置编码器
+func WithCallerEncoder(enc zapcore.CallerEncoder) Option {
+	return func(o *loggerOptions) { o.callerEncoder = enc }
+}
+
+// WithStacktraceKey 覆盖堆栈信息字段名，传空字符串表示禁用堆栈字段
+func WithStacktraceKey(key string) Option {
+	return func(o *loggerOptions) { o.stacktraceKey = key }
+}
+
+// WithStaticFields 为每条日志注入静态字段（如服务名、主机名、环境、trace-id key）
+func WithStaticFields(fields map[string]interface{}) Option {
+	return func(o *loggerOptions) {
+		for k, v := range fields {
+			o.staticFields = append(o.staticFields, zap.Any(k, v))
+		}
+	}
+}
+
+// buildEncoderConfig 基于开发环境默认配置叠加用户自定义的编码器覆盖项
+func buildEncoderConfig(o loggerOptions) zapcore.EncoderConfig {
+	cfg := zap.NewDevelopmentEncoderConfig()
+	cfg.EncodeTime = o.timeEncoder
+	cfg.EncodeLevel = o.levelEncoder
+	if o.callerEncoder != nil {
+		cfg.EncodeCaller = o.callerEncoder
+	}
+	cfg.StacktraceKey = o.stacktraceKey
+	return cfg
+}
+
+// buildEncoder 根据 EncoderKind 选择 console 或 JSON 编码器
+func buildEncoder(kind EncoderKind, cfg zapcore.EncoderConfig) zapcore.Encoder {
+	if kind == EncoderJSON {
+		return zapcore.NewJSONEncoder(cfg)
+	}
+	return zapcore.NewConsoleEncoder(cfg)
+}
+
+// InitLoggerWithOptions 是 InitLogger 的可配置版本，支持 JSON/console 编码器按核心独立选择，
+// 以及静态字段注入，例如 JSON 落盘、控制台仍为可读格式
+func InitLoggerWithOptions(warnFile, infoFile, debugFile string, warnPolicy, infoPolicy, debugPolicy RotationPolicy, compressMaxSave time.Duration, opts ...Option) {
+	o := defaultLoggerOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.compressor != nil {
+		SetCompressor(o.compressor)
+	}
+	if o.parallelWorkers != 0 || o.parallelThreshold != 0 {
+		setParallelCompression(o.parallelWorkers, o.parallelThreshold)
+	}
+	if o.retention != nil {
+		setRetentionPolicy(o.retention)
+	}
+
+	fileEncoderCfg := buildEncoderConfig(o)
+	fileEncoder := buildEncoder(o.fileEncoderKind, fileEncoderCfg)
+
+	var cores []zapcore.Core
+
+	if warnFile != "" {
+		warnWriter, err := newLevelWriter(warnFile, "warn", warnPolicy, o.warnBucket)
+		if err != nil {
+			zap.S().Panic(err)
+		}
+		warnBuffered := NewBufferedWriteSyncer(warnWriter)
+		registerCloser(warnBuffered)
+		cores = append(cores, zapcore.NewCore(fileEncoder, warnBuffered, zap.WarnLevel))
+	}
+
+	if infoFile != "" {
+		infoWriter, err := newLevelWriter(infoFile, "info", infoPolicy, o.infoBucket)
+		if err != nil {
+			zap.S().Panic(err)
+		}
+		infoBuffered := NewBufferedWriteSyncer(infoWriter)
+		registerCloser(infoBuffered)
+		cores = append(cores, zapcore.NewCore(fileEncoder, infoBuffered, zap.InfoLevel))
+	}
+
+	if debugFile != "" {
+		debugWriter, err := newLevelWriter(debugFile, "debug", debugPolicy, o.debugBucket)
+		if err != nil {
+			zap.S().Panic(err)
+		}
+		debugBuffered := NewBufferedWriteSyncer(debugWriter)
+		registerCloser(debugBuffered)
+		cores = append(cores, zapcore.NewCore(fileEncoder, debugBuffered, zap.DebugLevel))
+	}
+
+	consoleEncoder := buildEncoder(o.consoleEncoderKind, buildEncoderConfig(o))
+	cores = append(cores, zapcore.NewCore(consoleEncoder, zapcore.Lock(zapcore.AddSync(os.Stdout)), zap.DebugLevel))
+
+	core := zapcore.NewTee(cores...)
+	zapOpts := []zap.Option{zap.AddCaller()}
+	if len(o.staticFields) > 0 {
+		zapOpts = append(zapOpts, zap.Fields(o.staticFields...))
+	}
+	_logger := zap.New(core, zapOpts...)
+	zap.ReplaceGlobals(_logger)
+
+	go StartLogCompression(1, 0, 0, compressMaxSave, filepath.Dir(warnFile), filepath.Dir(infoFile), filepath.Dir(debugFile))
+}
+
+// newLevelWriter 按是否通过 With<Level>BucketPolicy 选择了分桶布局，在 NewBucketWriter 与
+// NewRotationWriter 之间二选一，让 time_bucket.go 的分桶写入器真正可以被 InitLoggerWithOptions 选用
+func newLevelWriter(fileName, level string, policy RotationPolicy, bucket *BucketPolicy) (zapcore.WriteSyncer, error) {
+	if bucket != nil {
+		return NewBucketWriter(filepath.Dir(fileName), level, *bucket)
+	}
+	return NewRotationWriter(fileName, policy)
+}
@@ -1,7 +1,6 @@
 package zaplogmanager
 
 import (
-	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -26,10 +25,12 @@ var (
 	dirLocks = &dirLockMap{
 		locks: make(map[string]*sync.Mutex),
 	}
-	processingDirs = make(map[string]bool)
-	processingMu   sync.Mutex
-	// 添加文件处理状态跟踪
-	processingFiles = make(map[string]bool)
+	// processingDirs/processingFiles 记录目录/文件 -> 处理到期时间（而非简单的 bool），
+	// 过期判断按条目自身的到期时间（staleEntryTTL，与 manager.go 的 fileStates 共用同一套口径），
+	// 不再依赖与条目年龄无关的全局 lastRunTime
+	processingDirs  = make(map[string]time.Time)
+	processingMu    sync.Mutex
+	processingFiles = make(map[string]time.Time)
 	filesMu         sync.Mutex
 )
 
@@ -53,9 +54,11 @@ func runCompressionJob(logDirs []string, compressMaxSave time.Duration) {
 
 	// 检查是否有正在处理的目录
 	processingMu.Lock()
-	// 清理过期的处理状态（超过5分钟未完成的任务）
-	for dir, _ := range processingDirs {
-		if time.Since(lastRunTime) > time.Minute*5 {
+	now := time.Now()
+	// 清理过期的处理状态：只清理真正超过自身 staleEntryTTL 期限的条目，
+	// 而不是所有目录一起按距上次运行的时间批量驱逐
+	for dir, deadline := range processingDirs {
+		if now.After(deadline) {
 			delete(processingDirs, dir)
 		}
 	}
@@ -63,11 +66,11 @@ func runCompressionJob(logDirs []string, compressMaxSave time.Duration) {
 	// 检查并标记要处理的目录
 	dirsToProcess := make([]string, 0)
 	for _, dir := range logDirs {
-		if processingDirs[dir] {
+		if deadline, busy := processingDirs[dir]; busy && now.Before(deadline) {
 			zap.S().Debugf("目录正在处理中，跳过: %v", dir)
 			continue
 		}
-		processingDirs[dir] = true
+		processingDirs[dir] = now.Add(staleEntryTTL)
 		dirsToProcess = append(dirsToProcess, dir)
 	}
 	processingMu.Unlock()
@@ -113,15 +116,6 @@ func runCompressionJob(logDirs []string, compressMaxSave time.Duration) {
 
 // processDirectory 处理单个目录
 func processDirectory(dir string, compressMaxSave time.Duration) error {
-	// 清理过期的文件处理状态
-	filesMu.Lock()
-	for file, _ := range processingFiles {
-		if time.Since(lastRunTime) > time.Minute*5 {
-			delete(processingFiles, file)
-		}
-	}
-	filesMu.Unlock()
-
 	// 处理历史日志压缩
 	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -132,23 +126,7 @@ func processDirectory(dir string, compressMaxSave time.Duration) error {
 		}
 
 		if !info.IsDir() {
-			// 检查文件是否正在处理
-			filesMu.Lock()
-			if processingFiles[path] {
-				filesMu.Unlock()
-				return nil
-			}
-			processingFiles[path] = true
-			filesMu.Unlock()
-
-			// 确保在处理完成后清理状态
-			defer func() {
-				filesMu.Lock()
-				delete(processingFiles, path)
-				filesMu.Unlock()
-			}()
-
-			if err := processFile(path); err != nil {
+			if err := processFileDeduped(path); err != nil {
 				zap.S().Errorf("文件处理失败: %v", err)
 			}
 		}
@@ -161,6 +139,37 @@ func processDirectory(dir string, compressMaxSave time.Duration) error {
 	return cleanExpiredGzLogs(dir, compressMaxSave)
 }
 
+// processFileDeduped 对单个文件去重后调用 processFile。fsnotify Manager 启用时（defaultManager非nil），
+// 复用它基于 inode+mtime 的 fileLease，让启动时的首次扫描、跨天任务这些轮询路径与事件驱动路径
+// 共享同一套去重状态；未启用时退回包级 processingFiles map，过期判断按条目自身的 staleEntryTTL
+func processFileDeduped(path string) error {
+	if mgr := DefaultManager(); mgr != nil {
+		return mgr.withFileLease(path, func() error { return processFile(path) })
+	}
+
+	now := time.Now()
+	filesMu.Lock()
+	for file, deadline := range processingFiles {
+		if now.After(deadline) {
+			delete(processingFiles, file)
+		}
+	}
+	if deadline, busy := processingFiles[path]; busy && now.Before(deadline) {
+		filesMu.Unlock()
+		return nil
+	}
+	processingFiles[path] = now.Add(staleEntryTTL)
+	filesMu.Unlock()
+
+	defer func() {
+		filesMu.Lock()
+		delete(processingFiles, path)
+		filesMu.Unlock()
+	}()
+
+	return processFile(path)
+}
+
 // processFile 处理单个文件
 func processFile(path string) error {
 	// 当日大文件检测逻辑
@@ -180,63 +189,21 @@ func processFile(path string) error {
 	return nil
 }
 
-// gzipLogFile 压缩单个日志文件为.gz格式
-func gzipLogFile(src string) error {
-	// 打开原文件
-	inFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := inFile.Close(); err != nil {
-			zap.S().Error(err)
-		}
-	}()
-
-	// 创建压缩文件（同名加.gz）
-	dst := src + ".gz"
-	outFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := outFile.Close(); err != nil {
-			zap.S().Error(err)
-		}
-	}()
-
-	// 使用gzip写入器
-	gzWriter := gzip.NewWriter(outFile)
-	defer func() {
-		if err := gzWriter.Close(); err != nil {
-			zap.S().Error(err)
-		}
-	}()
-
-	// 设置压缩头信息
-	gzWriter.Name = filepath.Base(src)
-	gzWriter.ModTime = time.Now()
-
-	// 执行压缩
-	if _, err = io.Copy(gzWriter, inFile); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// cleanExpiredGzLogs 清理过期压缩日志（包含原始.log和压缩的.gz）
+// cleanExpiredGzLogs 清理过期压缩日志（包含原始.log和压缩的归档文件，扩展名取决于当前激活的 Compressor）
 func cleanExpiredGzLogs(logDir string, maxSaveTime time.Duration) error {
 	cutoffDate := time.Now().Add(-maxSaveTime)
 	zap.S().Debugf("开始清理过期日志，截止日期：%s", cutoffDate.Format("2006-01-02"))
+	compressor := CurrentCompressor()
 
 	return filepath.Walk(logDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// 处理所有带日期的.gz文件
-		if filepath.Ext(path) != ".gz" || !dateRegex.MatchString(path) {
+		// 处理所有带日期的压缩归档文件；dateRegex 的 `^|[-_]` 锚点是相对文件名而非完整路径设计的，
+		// 传入全路径会被其间的目录分隔符挡住（如 syslog 默认路由 baseDir/host/20060102.log），
+		// 这里改用 filepath.Base 与 parseDateFromFileName 保持一致的"对文件名而非全路径匹配"口径
+		if !compressor.Match(path) || !dateRegex.MatchString(filepath.Base(path)) {
 			return nil
 		}
 
@@ -251,6 +218,9 @@ func cleanExpiredGzLogs(logDir string, maxSaveTime time.Duration) error {
 			zap.S().Infof("清理过期文件：%s (创建时间：%s)", path, fileDate.Format("2006-01-02"))
 			if err := os.Remove(path); err != nil {
 				zap.S().Errorf("删除过期文件失败：%v", err)
+				observeCompressionError("expire_remove")
+			} else {
+				observeFileExpired(path)
 			}
 		}
 
@@ -258,7 +228,8 @@ func cleanExpiredGzLogs(logDir string, maxSaveTime time.Duration) error {
 	})
 }
 
-// parseDateFromFileName 从文件名解析日期
+// parseDateFromFileName 从文件名解析日期，文件名本身未携带日期时回退读取所在时间分桶目录名
+// （形如 logs/info/202401151530/ 的分桶布局，见 bucketDirRegex）
 func parseDateFromFileName(path string) (time.Time, error) {
 	// 匹配格式示例:
 	// - log-20250507.1.gz
@@ -269,21 +240,20 @@ func parseDateFromFileName(path string) (time.Time, error) {
 		`(?:^|[-_./])(20\d{2})(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])(?:[-_.]|$)`,
 	)
 	matches := re.FindStringSubmatch(filepath.Base(path))
-	if len(matches) < 4 {
-		return time.Time{}, fmt.Errorf("invalid filename format")
+	if len(matches) >= 4 {
+		dateStr := fmt.Sprintf("%s%s%s", matches[1], matches[2], matches[3])
+		return time.Parse("20060102", dateStr)
 	}
 
-	// 提取年月日
-	year := matches[1]
-	month := matches[2]
-	day := matches[3]
+	// 回退：文件名中没有可解析的日期，尝试父目录名（时间分桶目录，如 202401151530）
+	if ts, ok := bucketTimeFromDirName(filepath.Base(filepath.Dir(path))); ok {
+		return ts, nil
+	}
 
-	// 尝试解析日期
-	dateStr := fmt.Sprintf("%s%s%s", year, month, day)
-	return time.Parse("20060102", dateStr)
+	return time.Time{}, fmt.Errorf("invalid filename format")
 }
 
-// safeCompress 安全压缩函数
+// safeCompress 安全压缩函数，使用当前激活的 Compressor（默认gzip，可通过 WithCompressor 切换）
 func safeCompress(path string) error {
 	// 双重检查文件存在
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -293,9 +263,17 @@ func safeCompress(path string) error {
 
 	zap.S().Debugf("开始压缩文件：%s", path)
 
-	if err := gzipLogFile(path); err != nil {
+	start := time.Now()
+	beforeSize := int64(0)
+	if fi, err := os.Stat(path); err == nil {
+		beforeSize = fi.Size()
+	}
+
+	if _, err := compressFileWithActive(path); err != nil {
+		observeCompressionError("compress")
 		return err
 	}
+	observeCompression(path, start, beforeSize)
 
 	// 压缩后二次确认删除
 	if _, err := os.Stat(path); err == nil {
@@ -314,8 +292,12 @@ func checkAndCompressCurrentLog(path string) bool {
 	// 获取文件大小
 	fi, err := os.Stat(path)
 	if err != nil || fi.Size() < maxCurrentSize {
+		if err == nil {
+			observeCurrentLogSize(path, fi.Size())
+		}
 		return false
 	}
+	observeCurrentLogSize(path, fi.Size())
 
 	// 执行带序号的压缩
 	for i := 0; i < 3; i++ {
@@ -349,21 +331,16 @@ func gzipLogFileWithIndex(src, dst string) error {
 		os.Remove(tmpFile) // 清理临时文件
 	}()
 
-	// 创建gzip写入器
-	gzWriter := gzip.NewWriter(outFile)
-	defer gzWriter.Close()
-
-	// 设置压缩头信息
-	gzWriter.Name = filepath.Base(src)
-	gzWriter.ModTime = time.Now()
+	// 使用当前激活的压缩器写入（默认gzip，可通过 WithCompressor 切换为 zstd/lz4）
+	writer := CurrentCompressor().NewWriter(outFile)
 
 	// 执行压缩
-	if _, err = io.Copy(gzWriter, inFile); err != nil {
+	if _, err = io.Copy(writer, inFile); err != nil {
 		return fmt.Errorf("压缩写入失败: %w", err)
 	}
 
 	// 确保所有数据都写入
-	if err = gzWriter.Close(); err != nil {
+	if err = writer.Close(); err != nil {
 		return fmt.Errorf("关闭压缩写入器失败: %w", err)
 	}
 	if err = outFile.Close(); err != nil {
@@ -379,10 +356,11 @@ func gzipLogFileWithIndex(src, dst string) error {
 	return nil
 }
 
-// compressCurrentLogWithIndex 带序号的当日日志压缩
+// compressCurrentLogWithIndex 带序号的当日日志压缩，压缩扩展名取决于当前激活的 Compressor
 func compressCurrentLogWithIndex(src string) error {
 	baseName := src
-	existingFiles, _ := filepath.Glob(baseName + ".*.gz")
+	ext := CurrentCompressor().Extension()
+	existingFiles, _ := filepath.Glob(baseName + ".*" + ext)
 
 	// 原子化序号生成
 	maxIndex := 0
@@ -394,13 +372,12 @@ func compressCurrentLogWithIndex(src string) error {
 	nextIndex := maxIndex + 1
 
 	// 带时间戳的压缩文件名
-	compressedName := fmt.Sprintf("%s.%d.gz", baseName, nextIndex)
+	compressedName := fmt.Sprintf("%s.%d%s", baseName, nextIndex, ext)
 	return gzipLogFileWithIndex(src, compressedName)
 }
 
 func existingIndex(f string) int {
-	re := regexp.MustCompile(`\.log\.(\d+)\.gz$`)
-	matches := re.FindStringSubmatch(f)
+	matches := existingIndexRegex().FindStringSubmatch(f)
 	if len(matches) < 2 {
 		return 0
 	}
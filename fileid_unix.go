@@ -0,0 +1,25 @@
+//go:build !windows
+
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileIdentity 返回文件的稳定标识（inode）及修改时间，用于跨事件去重
+func fileIdentity(path string) (string, time.Time, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fi.Name(), fi.ModTime(), nil
+	}
+
+	return fmt.Sprintf("inode-%d", stat.Ino), fi.ModTime(), nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package zaplogmanager
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileIdentity 在 Windows 上没有 POSIX inode 概念，退化为使用绝对路径作为标识
+func fileIdentity(path string) (string, time.Time, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return abs, fi.ModTime(), nil
+}
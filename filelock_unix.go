@@ -0,0 +1,63 @@
+//go:build !windows
+
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// Unix（linux/darwin/freebsd等）下基于 flock(2) 的进程级 advisory 文件锁实现
+
+// dirFileLock 持有一个目录下 .lock 哨兵文件的独占 flock
+type dirFileLock struct {
+	file *os.File
+}
+
+var (
+	dirLocksMu sync.Mutex
+	// openDirLocks 防止同一进程内针对同一目录重复 Open+Flock（flock对同一fd的多次持有会相互抵消）
+	openDirLocks = make(map[string]*sync.Mutex)
+)
+
+func processLocalLock(dir string) *sync.Mutex {
+	dirLocksMu.Lock()
+	defer dirLocksMu.Unlock()
+	if l, ok := openDirLocks[dir]; ok {
+		return l
+	}
+	l := &sync.Mutex{}
+	openDirLocks[dir] = l
+	return l
+}
+
+// acquireDirLock 对 dir/.lock 取独占的 flock，跨进程互斥；同时持有进程内互斥锁以保证同一进程内的 goroutine 串行化
+func acquireDirLock(dir string) (*dirFileLock, error) {
+	local := processLocalLock(dir)
+	local.Lock()
+
+	lockPath := dir + string(os.PathSeparator) + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		local.Unlock()
+		return nil, fmt.Errorf("打开锁文件失败: %w", err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		local.Unlock()
+		return nil, fmt.Errorf("加持flock失败: %w", err)
+	}
+
+	return &dirFileLock{file: f}, nil
+}
+
+// Unlock 释放 flock 并关闭哨兵文件
+func (l *dirFileLock) Unlock() {
+	defer processLocalLock(filepath.Dir(l.file.Name())).Unlock()
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	_ = l.file.Close()
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows 下基于 LockFileEx 的进程级 advisory 文件锁实现
+
+type dirFileLock struct {
+	file *os.File
+}
+
+var (
+	dirLocksMu   sync.Mutex
+	openDirLocks = make(map[string]*sync.Mutex)
+)
+
+func processLocalLock(dir string) *sync.Mutex {
+	dirLocksMu.Lock()
+	defer dirLocksMu.Unlock()
+	if l, ok := openDirLocks[dir]; ok {
+		return l
+	}
+	l := &sync.Mutex{}
+	openDirLocks[dir] = l
+	return l
+}
+
+// acquireDirLock 对 dir/.lock 取独占的 LockFileEx，跨进程互斥
+func acquireDirLock(dir string) (*dirFileLock, error) {
+	local := processLocalLock(dir)
+	local.Lock()
+
+	lockPath := filepath.Join(dir, ".lock")
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		local.Unlock()
+		return nil, fmt.Errorf("打开锁文件失败: %w", err)
+	}
+
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(
+		windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK,
+		0, 1, 0, ol,
+	); err != nil {
+		f.Close()
+		local.Unlock()
+		return nil, fmt.Errorf("加持LockFileEx失败: %w", err)
+	}
+
+	return &dirFileLock{file: f}, nil
+}
+
+// Unlock 释放 LockFileEx 并关闭哨兵文件
+func (l *dirFileLock) Unlock() {
+	defer processLocalLock(filepath.Dir(l.file.Name())).Unlock()
+	ol := new(windows.Overlapped)
+	_ = windows.UnlockFileEx(windows.Handle(l.file.Fd()), 0, 1, 0, ol)
+	_ = l.file.Close()
+}
@@ -1,9 +1,11 @@
 package zaplogmanager
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
@@ -15,28 +17,40 @@ import (
 
 // InitLogger 日志初始化结束后启动压缩任务
 // 参数说明: warnFile、infoFile、debugFile--表示三种等级日志包含路径的文件名，路径为空时不写入该类日志
-// newName: 表示替换文件名，建议设置为"-%Y%m%d.log" maxSaveTime: 原始日志最长保留时间，设置示例time.Hour*24*7
-// rotationTime: 日志切割时间，设置示例time.Hour*24 compressMaxSave: 压缩日志最长保留时间，设置示例time.Hour*24*30
-func InitLogger(warnFile, infoFile, debugFile, newName string, maxSaveTime, rotationTime, compressMaxSave time.Duration) {
+// warnPolicy、infoPolicy、debugPolicy: 每个级别独立的大小+时间混合轮转与保留策略，见 RotationPolicy
+// compressMaxSave: 压缩日志最长保留时间，设置示例time.Hour*24*30
+func InitLogger(warnFile, infoFile, debugFile string, warnPolicy, infoPolicy, debugPolicy RotationPolicy, compressMaxSave time.Duration) {
 	var cores []zapcore.Core
 
 	if warnFile != "" {
-		warnWriter := SetRotateRule(warnFile, newName, maxSaveTime, rotationTime)
+		warnWriter, err := NewRotationWriter(warnFile, warnPolicy)
+		if err != nil {
+			zap.S().Panic(err)
+		}
 		bufferedWarnWriter := NewBufferedWriteSyncer(warnWriter)
+		registerCloser(bufferedWarnWriter)
 		warnCore := zapcore.NewCore(GetConfig(), bufferedWarnWriter, zap.WarnLevel)
 		cores = append(cores, warnCore)
 	}
 
 	if infoFile != "" {
-		infoWriter := SetRotateRule(infoFile, newName, maxSaveTime, rotationTime)
+		infoWriter, err := NewRotationWriter(infoFile, infoPolicy)
+		if err != nil {
+			zap.S().Panic(err)
+		}
 		bufferedInfoWriter := NewBufferedWriteSyncer(infoWriter)
+		registerCloser(bufferedInfoWriter)
 		infoCore := zapcore.NewCore(GetConfig(), bufferedInfoWriter, zap.InfoLevel)
 		cores = append(cores, infoCore)
 	}
 
 	if debugFile != "" {
-		debugWriter := SetRotateRule(debugFile, newName, maxSaveTime, rotationTime)
+		debugWriter, err := NewRotationWriter(debugFile, debugPolicy)
+		if err != nil {
+			zap.S().Panic(err)
+		}
 		bufferedDebugWriter := NewBufferedWriteSyncer(debugWriter)
+		registerCloser(bufferedDebugWriter)
 		debugCore := zapcore.NewCore(GetConfig(), bufferedDebugWriter, zap.DebugLevel)
 		cores = append(cores, debugCore)
 	}
@@ -63,6 +77,7 @@ func GetConfig() zapcore.Encoder {
 }
 
 // SetRotateRule 设置日志切割规则
+// Deprecated: 仅支持按时间切割，已被 NewRotationWriter/RotationPolicy 的大小+时间混合方案取代，保留用于兼容旧调用方
 // 参数说明: fileName: 包含路径的文件名，如"./cim.log" newName: 替换文件名，建议设置为"-%Y%m%d.log"或"-%Y%m%d%H%M"；
 // maxSaveTime: 最长保存时间，建议设置为 time.Hour*24*30  rotationTime: 日志切割时间，建议设置为 time.Hour*24
 func SetRotateRule(fileName, newName string, maxSaveTime, rotationTime time.Duration) zapcore.WriteSyncer {
@@ -83,17 +98,64 @@ const (
 	bufferSize = 256 * 1024 // 256KB buffer
 )
 
-// BufferedWriteSyncer 带缓冲的写入器
+// OverflowPolicy 决定缓冲区写满后的处理方式
+type OverflowPolicy int
+
+const (
+	// WriteInline 缓冲区满时直接同步写入（原有默认行为）
+	WriteInline OverflowPolicy = iota
+	// Block 缓冲区满时阻塞直到有空位
+	Block
+	// DropOldest 缓冲区满时丢弃队列头部最旧的一条，为新数据腾出空间
+	DropOldest
+	// DropNewest 缓冲区满时直接丢弃本次写入的数据
+	DropNewest
+)
+
+// BufferedWriteSyncer 带缓冲、可配置溢出策略、支持优雅关闭的写入器
 type BufferedWriteSyncer struct {
-	buffer chan []byte
-	writer zapcore.WriteSyncer
+	buffer    chan []byte
+	writer    zapcore.WriteSyncer
+	overflow  OverflowPolicy
+	done      chan struct{}
+	flushed   chan struct{}
+	closeOnce sync.Once
+
+	// OnDrop 在因溢出策略丢弃 n 条日志时回调，可为空
+	OnDrop func(n int)
+	// OnFlush 在成功写入 bytes 字节日志时回调，可为空
+	OnFlush func(bytes int)
+}
+
+// BufferedOption 配置 BufferedWriteSyncer 的函数式选项
+type BufferedOption func(*BufferedWriteSyncer)
+
+// WithOverflowPolicy 设置缓冲区写满后的处理策略，默认 WriteInline
+func WithOverflowPolicy(policy OverflowPolicy) BufferedOption {
+	return func(ws *BufferedWriteSyncer) { ws.overflow = policy }
+}
+
+// WithDropHook 设置丢弃日志时的监控回调
+func WithDropHook(fn func(n int)) BufferedOption {
+	return func(ws *BufferedWriteSyncer) { ws.OnDrop = fn }
+}
+
+// WithFlushHook 设置成功刷盘时的监控回调
+func WithFlushHook(fn func(bytes int)) BufferedOption {
+	return func(ws *BufferedWriteSyncer) { ws.OnFlush = fn }
 }
 
 // NewBufferedWriteSyncer 创建新的带缓冲的写入器
-func NewBufferedWriteSyncer(writer zapcore.WriteSyncer) *BufferedWriteSyncer {
+func NewBufferedWriteSyncer(writer zapcore.WriteSyncer, opts ...BufferedOption) *BufferedWriteSyncer {
 	ws := &BufferedWriteSyncer{
-		buffer: make(chan []byte, bufferSize),
-		writer: writer,
+		buffer:   make(chan []byte, bufferSize),
+		writer:   writer,
+		overflow: WriteInline,
+		done:     make(chan struct{}),
+		flushed:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(ws)
 	}
 	go ws.flushRoutine()
 	return ws
@@ -109,22 +171,141 @@ func (ws *BufferedWriteSyncer) Write(p []byte) (n int, err error) {
 	case ws.buffer <- data:
 		return len(p), nil
 	default:
-		// 如果缓冲区满，直接写入
+		return ws.handleOverflow(p, data)
+	}
+}
+
+// handleOverflow 根据 OverflowPolicy 处理缓冲区已满的写入
+func (ws *BufferedWriteSyncer) handleOverflow(p, data []byte) (int, error) {
+	switch ws.overflow {
+	case Block:
+		select {
+		case ws.buffer <- data:
+			return len(p), nil
+		case <-ws.done:
+			return ws.writer.Write(p)
+		}
+	case DropOldest:
+		select {
+		case <-ws.buffer:
+			ws.notifyDrop(1)
+		default:
+		}
+		select {
+		case ws.buffer <- data:
+		default:
+			ws.notifyDrop(1)
+		}
+		return len(p), nil
+	case DropNewest:
+		ws.notifyDrop(1)
+		return len(p), nil
+	default: // WriteInline
 		return ws.writer.Write(p)
 	}
 }
 
-// Sync 实现 zapcore.WriteSyncer
+func (ws *BufferedWriteSyncer) notifyDrop(n int) {
+	if ws.OnDrop != nil {
+		ws.OnDrop(n)
+	}
+}
+
+// Sync 实现 zapcore.WriteSyncer：先排空缓冲区中已入队的数据，再同步底层写入器
 func (ws *BufferedWriteSyncer) Sync() error {
+	ws.drain()
 	return ws.writer.Sync()
 }
 
-// flushRoutine 异步刷新缓冲区
+// Close 发出关闭信号并等待 flushRoutine 排空缓冲区后同步底层写入器，用于进程退出前的优雅关闭。
+// 注意：只关闭 done，不关闭 buffer —— 仍可能有并发 Write/handleOverflow 往 buffer 发送数据
+// （例如 Shutdown 执行期间另一个goroutine仍在打日志），关闭一个还有生产者在发送的channel会panic
+func (ws *BufferedWriteSyncer) Close() error {
+	ws.closeOnce.Do(func() {
+		close(ws.done)
+	})
+	<-ws.flushed
+	return ws.writer.Sync()
+}
+
+// drain 非阻塞地把当前已入队但尚未写入的数据立即写完，供 Sync 使用
+func (ws *BufferedWriteSyncer) drain() {
+	for {
+		select {
+		case data := <-ws.buffer:
+			ws.writeAndCount(data)
+		default:
+			return
+		}
+	}
+}
+
+func (ws *BufferedWriteSyncer) writeAndCount(data []byte) {
+	n, err := ws.writer.Write(data)
+	if err != nil {
+		zap.L().Error("Failed to write log", zap.Error(err))
+		return
+	}
+	if ws.OnFlush != nil {
+		ws.OnFlush(n)
+	}
+}
+
+// flushRoutine 异步刷新缓冲区，done 关闭后排空缓冲区中剩余数据，再关闭 flushed 以通知 Close 完成排空
 func (ws *BufferedWriteSyncer) flushRoutine() {
-	for data := range ws.buffer {
-		_, err := ws.writer.Write(data)
-		if err != nil {
-			zap.L().Error("Failed to write log", zap.Error(err))
+	defer close(ws.flushed)
+	for {
+		select {
+		case data := <-ws.buffer:
+			ws.writeAndCount(data)
+		case <-ws.done:
+			for {
+				select {
+				case data := <-ws.buffer:
+					ws.writeAndCount(data)
+				default:
+					return
+				}
+			}
 		}
 	}
 }
+
+// loggerClosers 记录 InitLogger/InitLoggerWithOptions 创建的所有可关闭的缓冲写入器
+var (
+	loggerClosersMu sync.Mutex
+	loggerClosers   []*BufferedWriteSyncer
+)
+
+// registerCloser 将缓冲写入器登记到全局关闭列表，供 Shutdown 统一调用
+func registerCloser(ws *BufferedWriteSyncer) {
+	loggerClosersMu.Lock()
+	defer loggerClosersMu.Unlock()
+	loggerClosers = append(loggerClosers, ws)
+}
+
+// Shutdown 在进程退出前排空并刷新所有级别的缓冲写入器
+func Shutdown(ctx context.Context) error {
+	loggerClosersMu.Lock()
+	closers := make([]*BufferedWriteSyncer, len(loggerClosers))
+	copy(closers, loggerClosers)
+	loggerClosersMu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		var firstErr error
+		for _, c := range closers {
+			if err := c.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		done <- firstErr
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
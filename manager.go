@@ -0,0 +1,172 @@
+package zaplogmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// 压缩调度管理器模块：用 fsnotify 事件驱动 + 进程级文件锁 + 按 inode/mtime 去重的 sync.Map
+// 取代此前基于包级 map 和“距上次运行超过5分钟即视为过期”这种与条目自身年龄无关的去重方案
+
+// staleEntryTTL 单个文件状态条目的最长存活时间，超过该时间视为处理已异常中断，允许重新入队
+const staleEntryTTL = 5 * time.Minute
+
+// Manager 基于文件锁与 fsnotify 的压缩调度器
+type Manager struct {
+	logDirs         []string
+	compressMaxSave time.Duration
+
+	watcher *fsnotify.Watcher
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// fileStates 按 inode+mtime 去重记录正在处理的文件及其处理截止时间
+	fileStates sync.Map // key: string -> time.Time(deadline)
+}
+
+// NewManager 创建一个监控 logDirs 的压缩调度管理器
+func NewManager(compressMaxSave time.Duration, logDirs ...string) *Manager {
+	return &Manager{
+		logDirs:         logDirs,
+		compressMaxSave: compressMaxSave,
+	}
+}
+
+// WithFsnotifyWatch 让 StartLogCompressionWithOptions 用本文件的事件驱动 Manager 取代
+// 轮询式的 size-monitor 定时任务；跨天压缩/保留策略的定时任务不受影响，仍由 Scheduler 负责
+func WithFsnotifyWatch() Option {
+	return func(o *loggerOptions) { o.fsnotifyWatch = true }
+}
+
+// defaultManager 由 StartLogCompressionWithOptions 在启用 WithFsnotifyWatch 时创建
+var defaultManager *Manager
+
+// DefaultManager 返回当前生效的 fsnotify Manager，未启用 WithFsnotifyWatch 时为 nil
+func DefaultManager() *Manager {
+	return defaultManager
+}
+
+// Start 启动 fsnotify 监听与事件处理循环，替代原先 `for range ticker.C` 且23小时内不可取消的轮询
+func (m *Manager) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建fsnotify监听器失败: %w", err)
+	}
+	m.watcher = watcher
+
+	for _, dir := range m.logDirs {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			zap.S().Errorf("创建日志目录失败: %s -> %v", dir, err)
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			zap.S().Errorf("监听目录失败: %s -> %v", dir, err)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go m.loop(runCtx)
+
+	return nil
+}
+
+// Stop 停止事件监听循环并等待其退出
+func (m *Manager) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	if m.watcher != nil {
+		return m.watcher.Close()
+	}
+	return nil
+}
+
+func (m *Manager) loop(ctx context.Context) {
+	defer m.wg.Done()
+	for {
+		select {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			m.handleEvent(event)
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			zap.S().Errorf("fsnotify监听错误: %v", err)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleEvent 响应 rotatelogs/rotateWriter 产生的写入、重命名、创建事件，触发一次压缩检查
+func (m *Manager) handleEvent(event fsnotify.Event) {
+	path := event.Name
+	if !(logExtRegex.MatchString(path) || CurrentCompressor().Match(path)) {
+		return
+	}
+	if event.Op&(fsnotify.Write|fsnotify.Rename|fsnotify.Create) == 0 {
+		return
+	}
+
+	if err := m.withFileLease(path, func() error {
+		return processFile(path)
+	}); err != nil {
+		zap.S().Errorf("处理文件失败: %s -> %v", path, err)
+	}
+}
+
+// withFileLease 先取目录级advisory文件锁（跨进程互斥），再按 inode+mtime 去重（跨goroutine互斥），
+// 去重条目带独立的过期时间，不再依赖"距离上次全局运行时间"这种与条目自身年龄无关的判断
+func (m *Manager) withFileLease(path string, fn func() error) error {
+	dirLock, err := acquireDirLock(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("获取目录文件锁失败: %w", err)
+	}
+	defer dirLock.Unlock()
+
+	key, ok := fileStateKey(path)
+	if !ok {
+		// 文件可能已被并发处理删除/重命名，跳过本次事件
+		return nil
+	}
+
+	now := time.Now()
+	if existingDeadline, loaded := m.fileStates.Load(key); loaded {
+		if now.Before(existingDeadline.(time.Time)) {
+			zap.S().Debugf("文件正在处理中，跳过: %s", path)
+			return nil
+		}
+		// 超过 staleEntryTTL 仍未清理，视为异常中断，允许重新处理
+	}
+
+	m.fileStates.Store(key, now.Add(staleEntryTTL))
+	defer m.fileStates.Delete(key)
+
+	return fn()
+}
+
+// fileStateKey 基于 inode（或等价标识）+ mtime 生成去重键，使同一物理文件内容的重复事件被正确合并
+func fileStateKey(path string) (string, bool) {
+	id, mtime, err := fileIdentity(path)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s@%d", id, mtime.UnixNano()), true
+}
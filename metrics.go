@@ -0,0 +1,144 @@
+package zaplogmanager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus 指标模块：为压缩子系统提供计数器/仪表盘/直方图，补上此前 runCompressionJob
+// 只把错误丢进 zap.S().Errorf、完全没有可观测性的缺口
+
+var (
+	metricsEnabled bool
+
+	filesCompressedTotal  *prometheus.CounterVec
+	filesExpiredTotal     *prometheus.CounterVec
+	compressionErrorsTotal *prometheus.CounterVec
+	currentLogBytes       *prometheus.GaugeVec
+	lastRunTimestamp      prometheus.Gauge
+	compressionDuration   prometheus.Histogram
+	compressionRatio      prometheus.Histogram
+)
+
+// RegisterMetrics 创建并注册压缩子系统的 Prometheus 指标。未调用本函数时，所有埋点均为空操作
+func RegisterMetrics(reg prometheus.Registerer) {
+	filesCompressedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logmanager_files_compressed_total",
+		Help: "已成功压缩的日志文件总数",
+	}, []string{"level"})
+
+	filesExpiredTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logmanager_files_expired_total",
+		Help: "已清理的过期压缩日志文件总数",
+	}, []string{"level"})
+
+	compressionErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logmanager_compression_errors_total",
+		Help: "压缩/清理流程中发生的错误总数",
+	}, []string{"reason"})
+
+	currentLogBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "logmanager_current_log_bytes",
+		Help: "当前正在写入的日志文件大小（字节）",
+	}, []string{"level"})
+
+	lastRunTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "logmanager_last_run_timestamp",
+		Help: "最近一次压缩任务执行的Unix时间戳",
+	})
+
+	compressionDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "logmanager_compression_duration_seconds",
+		Help:    "单次文件压缩耗时分布",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	compressionRatio = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "logmanager_compression_ratio",
+		Help:    "压缩后/压缩前的体积比，越小压缩效果越好",
+		Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+	})
+
+	reg.MustRegister(
+		filesCompressedTotal,
+		filesExpiredTotal,
+		compressionErrorsTotal,
+		currentLogBytes,
+		lastRunTimestamp,
+		compressionDuration,
+		compressionRatio,
+	)
+
+	metricsEnabled = true
+}
+
+// levelFromPath 从日志文件/目录路径中提取级别标签，约定路径形如 .../<level>/xxx.log
+func levelFromPath(path string) string {
+	dir := filepath.Dir(path)
+	level := strings.ToLower(filepath.Base(dir))
+	switch level {
+	case "warn", "info", "debug":
+		return level
+	default:
+		return "unknown"
+	}
+}
+
+// observeCompression 记录一次压缩的耗时、压缩比与成功计数
+func observeCompression(path string, start time.Time, beforeSize int64) {
+	if !metricsEnabled {
+		return
+	}
+	level := levelFromPath(path)
+	filesCompressedTotal.WithLabelValues(level).Inc()
+	compressionDuration.Observe(time.Since(start).Seconds())
+
+	if afterSize, err := compressedSize(path); err == nil && beforeSize > 0 {
+		compressionRatio.Observe(float64(afterSize) / float64(beforeSize))
+	}
+}
+
+// compressedSize 返回压缩产物（当前激活 Compressor 扩展名）的大小
+func compressedSize(originalPath string) (int64, error) {
+	fi, err := os.Stat(originalPath + CurrentCompressor().Extension())
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// observeCompressionError 记录一次压缩/清理错误
+func observeCompressionError(reason string) {
+	if !metricsEnabled {
+		return
+	}
+	compressionErrorsTotal.WithLabelValues(reason).Inc()
+}
+
+// observeFileExpired 记录一次过期文件清理
+func observeFileExpired(path string) {
+	if !metricsEnabled {
+		return
+	}
+	filesExpiredTotal.WithLabelValues(levelFromPath(path)).Inc()
+}
+
+// observeCurrentLogSize 更新当前正在写入的日志文件大小仪表盘
+func observeCurrentLogSize(path string, size int64) {
+	if !metricsEnabled {
+		return
+	}
+	currentLogBytes.WithLabelValues(levelFromPath(path)).Set(float64(size))
+}
+
+// observeRunTimestamp 更新最近一次压缩任务运行时间戳
+func observeRunTimestamp() {
+	if !metricsEnabled {
+		return
+	}
+	lastRunTimestamp.Set(float64(time.Now().Unix()))
+}
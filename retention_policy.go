@@ -0,0 +1,203 @@
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 保留策略模块：在原先只支持单一 compressMaxSave 的基础上，补充 lumberjack 风格的
+// MaxBackups/MaxAge/MaxSize 语义，并支持按磁盘剩余空间触发紧急清理
+
+// RetentionPolicy 描述压缩日志的保留策略，各字段语义对齐 lumberjack：0 表示该维度不限制，
+// 删除按文件名中嵌入的时间戳（而非mtime）从旧到新进行
+type RetentionPolicy struct {
+	MaxAgeDays        int
+	MaxBackups        int
+	MaxTotalSizeBytes int64
+	MinFreeDiskBytes  int64
+}
+
+var (
+	retentionMu     sync.RWMutex
+	activeRetention *RetentionPolicy
+)
+
+// WithRetentionPolicy 是 InitLoggerWithOptions/StartLogCompressionWithOptions 的函数式选项，用于启用保留策略
+func WithRetentionPolicy(p RetentionPolicy) Option {
+	return func(o *loggerOptions) { o.retention = &p }
+}
+
+func setRetentionPolicy(p *RetentionPolicy) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	activeRetention = p
+}
+
+func currentRetentionPolicy() (RetentionPolicy, bool) {
+	retentionMu.RLock()
+	defer retentionMu.RUnlock()
+	if activeRetention == nil {
+		return RetentionPolicy{}, false
+	}
+	return *activeRetention, true
+}
+
+// retainedFile 是参与保留策略判定的压缩文件及其元信息
+type retainedFile struct {
+	path string
+	ts   time.Time
+	size int64
+}
+
+// enforceRetentionAll 对多个日志目录依次执行保留策略，调用方需自行持有 fileLock
+func enforceRetentionAll(logDirs []string) {
+	policy, ok := currentRetentionPolicy()
+	if !ok {
+		return
+	}
+	for _, dir := range logDirs {
+		if dir == "" {
+			continue
+		}
+		if err := enforceRetention(dir, policy); err != nil {
+			zap.S().Errorf("保留策略执行失败: %s -> %v", dir, err)
+			observeCompressionError("retention")
+		}
+	}
+}
+
+// enforceRetention 按 MaxAgeDays/MaxBackups/MaxTotalSizeBytes/MinFreeDiskBytes 清理单个目录下的压缩日志
+func enforceRetention(dir string, policy RetentionPolicy) error {
+	files, err := listRetainedFiles(dir)
+	if err != nil {
+		return fmt.Errorf("列举压缩文件失败: %w", err)
+	}
+
+	if policy.MaxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -policy.MaxAgeDays)
+		files = removeIf(files, func(f retainedFile) bool {
+			return f.ts.Before(cutoff)
+		}, "max_age")
+	}
+
+	if policy.MaxBackups > 0 && len(files) > policy.MaxBackups {
+		excess := len(files) - policy.MaxBackups
+		files = removeOldest(files, excess, "max_backups")
+	}
+
+	if policy.MaxTotalSizeBytes > 0 {
+		files = enforceMaxTotalSize(files, policy.MaxTotalSizeBytes)
+	}
+
+	if policy.MinFreeDiskBytes > 0 {
+		if err := enforceMinFreeDisk(dir, files, policy.MinFreeDiskBytes); err != nil {
+			zap.S().Errorf("磁盘空间检查失败: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// listRetainedFiles 列举目录下所有当前压缩器产生的归档文件，按时间戳升序（最旧在前）排列
+func listRetainedFiles(dir string) ([]retainedFile, error) {
+	compressor := CurrentCompressor()
+	var files []retainedFile
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !compressor.Match(path) {
+			return nil
+		}
+		ts, err := parseDateFromFileName(path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, retainedFile{path: path, ts: ts, size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ts.Before(files[j].ts) })
+	return files, nil
+}
+
+// removeIf 删除所有满足 pred 的文件，并记录结构化日志+指标
+func removeIf(files []retainedFile, pred func(retainedFile) bool, reason string) []retainedFile {
+	kept := files[:0]
+	for _, f := range files {
+		if pred(f) {
+			deleteRetainedFile(f, reason)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// removeOldest 删除列表头部（最旧）的 n 个文件
+func removeOldest(files []retainedFile, n int, reason string) []retainedFile {
+	for i := 0; i < n && i < len(files); i++ {
+		deleteRetainedFile(files[i], reason)
+	}
+	if n >= len(files) {
+		return files[:0]
+	}
+	return files[n:]
+}
+
+// enforceMaxTotalSize 从最旧的文件开始删除，直到总大小不超过 maxTotalSize
+func enforceMaxTotalSize(files []retainedFile, maxTotalSize int64) []retainedFile {
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+
+	idx := 0
+	for total > maxTotalSize && idx < len(files) {
+		deleteRetainedFile(files[idx], "max_total_size")
+		total -= files[idx].size
+		idx++
+	}
+	return files[idx:]
+}
+
+// enforceMinFreeDisk 在磁盘剩余空间低于阈值时，从最旧的文件开始紧急删除直至恢复阈值以上
+func enforceMinFreeDisk(dir string, files []retainedFile, minFree int64) error {
+	for i := 0; i < len(files); i++ {
+		free, err := diskFreeBytes(dir)
+		if err != nil {
+			return err
+		}
+		if free >= minFree {
+			return nil
+		}
+		zap.S().Warnw("磁盘剩余空间不足，触发紧急清理",
+			"dir", dir, "free_bytes", free, "min_free_bytes", minFree)
+		deleteRetainedFile(files[i], "min_free_disk")
+	}
+	return nil
+}
+
+func deleteRetainedFile(f retainedFile, reason string) {
+	if err := os.Remove(f.path); err != nil {
+		zap.S().Errorf("保留策略删除文件失败: %s (原因:%s) -> %v", f.path, reason, err)
+		observeCompressionError("retention_remove")
+		return
+	}
+	zap.S().Infow("保留策略删除过期/超量日志文件",
+		"path", f.path, "reason", reason, "timestamp", f.ts.Format("2006-01-02"), "size", f.size)
+	observeFileExpired(f.path)
+}
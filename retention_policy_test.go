@@ -0,0 +1,75 @@
+package zaplogmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func testDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRemoveOldest(t *testing.T) {
+	files := []retainedFile{
+		{path: "/tmp/zaplogmanager-test-retention-a.gz", ts: testDate(2024, 1, 1)},
+		{path: "/tmp/zaplogmanager-test-retention-b.gz", ts: testDate(2024, 1, 2)},
+		{path: "/tmp/zaplogmanager-test-retention-c.gz", ts: testDate(2024, 1, 3)},
+	}
+
+	kept := removeOldest(files, 1, "max_backups")
+	if len(kept) != 2 {
+		t.Fatalf("期望保留2个文件，实际保留%d个", len(kept))
+	}
+	if kept[0].path != files[1].path || kept[1].path != files[2].path {
+		t.Errorf("removeOldest 应当删除最旧的条目，保留顺序应不变: %+v", kept)
+	}
+}
+
+func TestRemoveOldestExceedsLength(t *testing.T) {
+	files := []retainedFile{
+		{path: "/tmp/zaplogmanager-test-retention-a.gz", ts: testDate(2024, 1, 1)},
+	}
+	kept := removeOldest(files, 5, "max_backups")
+	if len(kept) != 0 {
+		t.Errorf("n超过切片长度时应当清空，实际剩余%d个", len(kept))
+	}
+}
+
+func TestEnforceMaxTotalSize(t *testing.T) {
+	files := []retainedFile{
+		{path: "/tmp/zaplogmanager-test-retention-a.gz", ts: testDate(2024, 1, 1), size: 100},
+		{path: "/tmp/zaplogmanager-test-retention-b.gz", ts: testDate(2024, 1, 2), size: 100},
+		{path: "/tmp/zaplogmanager-test-retention-c.gz", ts: testDate(2024, 1, 3), size: 100},
+	}
+
+	kept := enforceMaxTotalSize(files, 150)
+	if len(kept) != 1 {
+		t.Fatalf("总大小上限150，单文件100，期望只保留最新的1个，实际保留%d个", len(kept))
+	}
+	if kept[0].path != files[2].path {
+		t.Errorf("应当保留最新的文件，实际保留: %s", kept[0].path)
+	}
+}
+
+func TestEnforceMaxTotalSizeUnderLimit(t *testing.T) {
+	files := []retainedFile{
+		{path: "/tmp/zaplogmanager-test-retention-a.gz", ts: testDate(2024, 1, 1), size: 10},
+	}
+	kept := enforceMaxTotalSize(files, 1000)
+	if len(kept) != 1 {
+		t.Errorf("未超出总大小上限时不应删除任何文件，实际保留%d个", len(kept))
+	}
+}
+
+func TestRemoveIf(t *testing.T) {
+	cutoff := testDate(2024, 1, 2)
+	files := []retainedFile{
+		{path: "/tmp/zaplogmanager-test-retention-a.gz", ts: testDate(2024, 1, 1)},
+		{path: "/tmp/zaplogmanager-test-retention-b.gz", ts: testDate(2024, 1, 3)},
+	}
+
+	kept := removeIf(files, func(f retainedFile) bool { return f.ts.Before(cutoff) }, "max_age")
+	if len(kept) != 1 || kept[0].path != files[1].path {
+		t.Errorf("removeIf 未按预期过滤超过MaxAgeDays的文件: %+v", kept)
+	}
+}
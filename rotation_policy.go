@@ -0,0 +1,239 @@
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 轮转策略模块：按大小和时间混合触发的日志轮转，替代 SetRotateRule 的纯时间切割方案
+
+// RotationPolicy 描述单个日志级别的轮转与保留策略
+// MaxSizeMB: 单文件达到该大小（MB）后触发轮转，0 表示不按大小轮转
+// MaxBackups: 保留的历史备份文件数量上限，0 表示不限制
+// MaxAgeDays: 备份文件最长保留天数，0 表示不限制
+// RotationInterval: 达到该时间间隔后触发轮转（即使未达到 MaxSizeMB），0 表示不按时间轮转
+// Compress: 是否在后台goroutine中用当前激活的 Compressor（默认gzip）压缩轮转出的备份文件
+type RotationPolicy struct {
+	MaxSizeMB        int
+	MaxBackups       int
+	MaxAgeDays       int
+	RotationInterval time.Duration
+	Compress         bool
+}
+
+// backupNameRegex 匹配 rotateWriter 产生的备份文件名：basename-20060102-150405.NNN.log，
+// 压缩后会追加当前激活 Compressor 的扩展名（如 .gz/.zst/.lz4）
+var backupNameRegex = regexp.MustCompile(`-(\d{8}-\d{6})\.(\d{3})\.log(\.\w+)?$`)
+
+// rotateWriter 按大小和时间混合策略轮转的写入器
+type rotateWriter struct {
+	mu           sync.Mutex
+	baseName     string // 不含 .log 后缀的完整路径前缀
+	policy       RotationPolicy
+	file         *os.File
+	size         int64
+	openedAt     time.Time
+}
+
+// NewRotationWriter 根据 RotationPolicy 创建一个混合大小/时间轮转的 WriteSyncer
+func NewRotationWriter(fileName string, policy RotationPolicy) (zapcore.WriteSyncer, error) {
+	rw := &rotateWriter{
+		baseName: strings.TrimSuffix(fileName, ".log"),
+		policy:   policy,
+	}
+	if err := rw.openExisting(); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (rw *rotateWriter) logPath() string {
+	return rw.baseName + ".log"
+}
+
+// openExisting 打开（或创建）当前日志文件，并恢复已有大小信息
+func (rw *rotateWriter) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(rw.logPath()), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(rw.logPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("读取日志文件状态失败: %w", err)
+	}
+
+	rw.file = f
+	rw.size = fi.Size()
+	rw.openedAt = fi.ModTime()
+	if rw.size == 0 {
+		rw.openedAt = time.Now()
+	}
+	return nil
+}
+
+// Write 实现 io.Writer，按需在写入前触发轮转
+func (rw *rotateWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.shouldRotate(len(p)) {
+		if err := rw.rotate(); err != nil {
+			zap.S().Errorf("日志轮转失败: %v", err)
+		}
+	}
+
+	n, err := rw.file.Write(p)
+	rw.size += int64(n)
+	return n, err
+}
+
+// Sync 实现 zapcore.WriteSyncer
+func (rw *rotateWriter) Sync() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.file.Sync()
+}
+
+func (rw *rotateWriter) shouldRotate(nextWrite int) bool {
+	if rw.policy.MaxSizeMB > 0 && rw.size+int64(nextWrite) > int64(rw.policy.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	if rw.policy.RotationInterval > 0 && time.Since(rw.openedAt) >= rw.policy.RotationInterval {
+		return true
+	}
+	return false
+}
+
+// rotate 关闭当前文件，将其重命名为带时间戳的备份文件，并打开新的当前文件
+func (rw *rotateWriter) rotate() error {
+	if err := rw.file.Close(); err != nil {
+		return fmt.Errorf("关闭当前日志文件失败: %w", err)
+	}
+
+	backupName := rw.nextBackupName()
+	if err := os.Rename(rw.logPath(), backupName); err != nil {
+		// 重命名失败时原文件仍在 logPath()，重新打开以避免 rw.file 永久保持已关闭状态
+		if reopenErr := rw.openExisting(); reopenErr != nil {
+			zap.S().Errorf("轮转失败后重新打开日志文件也失败: %v", reopenErr)
+		}
+		return fmt.Errorf("重命名备份文件失败: %w", err)
+	}
+
+	if rw.policy.Compress {
+		go compressBackupInBackground(backupName)
+	}
+
+	if err := rw.openExisting(); err != nil {
+		return err
+	}
+
+	go rw.enforceBackupLimits()
+	return nil
+}
+
+// nextBackupName 生成形如 basename-YYYYMMDD-HHMMSS.NNN.log 的备份文件名，避免同秒内的碰撞
+func (rw *rotateWriter) nextBackupName() string {
+	ts := time.Now().Format("20060102-150405")
+	for seq := 0; seq < 1000; seq++ {
+		name := fmt.Sprintf("%s-%s.%03d.log", rw.baseName, ts, seq)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+	}
+	// 理论上不会走到这里，兜底使用纳秒避免死循环
+	return fmt.Sprintf("%s-%s.%d.log", rw.baseName, ts, time.Now().UnixNano())
+}
+
+// compressBackupInBackground 在后台goroutine中用当前激活的 Compressor 压缩轮转出的备份文件，避免阻塞写入路径
+func compressBackupInBackground(path string) {
+	dst := path + CurrentCompressor().Extension()
+	if err := gzipLogFileWithIndex(path, dst); err != nil {
+		zap.S().Errorf("轮转备份文件压缩失败: %s -> %v", path, err)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		zap.S().Errorf("删除已压缩的备份文件失败: %s -> %v", path, err)
+	}
+}
+
+// enforceBackupLimits 按 MaxBackups/MaxAgeDays 清理多余或过期的备份文件
+
+func (rw *rotateWriter) enforceBackupLimits() {
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	backups, err := listBackups(rw.baseName)
+	if err != nil {
+		zap.S().Errorf("列举备份文件失败: %v", err)
+		return
+	}
+
+	if rw.policy.MaxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -rw.policy.MaxAgeDays)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.ts.Before(cutoff) {
+				if err := os.Remove(b.path); err != nil {
+					zap.S().Errorf("删除过期备份失败: %s -> %v", b.path, err)
+				}
+				continue
+			}
+			kept = append(kept, b)
+		}
+		backups = kept
+	}
+
+	if rw.policy.MaxBackups > 0 && len(backups) > rw.policy.MaxBackups {
+		// backups 已按时间升序排列，最旧的排在前面
+		excess := backups[:len(backups)-rw.policy.MaxBackups]
+		for _, b := range excess {
+			if err := os.Remove(b.path); err != nil {
+				zap.S().Errorf("删除多余备份失败: %s -> %v", b.path, err)
+			}
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	ts   time.Time
+}
+
+// listBackups 返回给定前缀下所有备份文件（含已压缩的.gz），按时间戳升序排列
+func listBackups(baseName string) ([]backupFile, error) {
+	matches, err := filepath.Glob(baseName + "-*.log*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]backupFile, 0, len(matches))
+	for _, m := range matches {
+		sub := backupNameRegex.FindStringSubmatch(m)
+		if len(sub) < 3 {
+			continue
+		}
+		ts, err := time.ParseInLocation("20060102-150405", sub[1], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: m, ts: ts})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ts.Before(backups[j].ts) })
+	return backups, nil
+}
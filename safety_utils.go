@@ -23,8 +23,10 @@ func safeRunCompressionJob(logDirs []string, compressMaxSave time.Duration) {
 	defer func() {
 		if err := recover(); err != nil {
 			zap.S().Errorf("日志压缩任务异常: %v", err)
+			observeCompressionError("panic")
 		}
 	}()
 
 	runCompressionJob(logDirs, compressMaxSave)
+	observeRunTimestamp()
 }
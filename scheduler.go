@@ -0,0 +1,126 @@
+package zaplogmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// 调度器模块：用 cron 表达式替代 scheduleDailyJob 硬编码的单一每日触发时刻。
+// 旧实现里的 isTargetHour(next, 1) 是个潜伏bug——只有在 hour==1 调度时才会真正执行，
+// 这里改为直接以 cron 表达式触发，不再需要这层多余判断
+
+// JobOption 配置单个调度任务的函数式选项
+type JobOption func(*schedulerJob)
+
+// WithJobMinInterval 覆盖任务的最小执行间隔去抖（默认沿用包级 minInterval，即5秒）
+func WithJobMinInterval(d time.Duration) JobOption {
+	return func(j *schedulerJob) { j.minInterval = d }
+}
+
+type schedulerJob struct {
+	name        string
+	cronExpr    string
+	fn          func(ctx context.Context)
+	minInterval time.Duration
+	entryID     cron.EntryID
+
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+// run 执行任务，保留原 safeRunCompressionJob 的去抖+panic恢复语义，但按任务独立计时而非全局共享
+func (j *schedulerJob) run(ctx context.Context) {
+	j.mu.Lock()
+	if time.Since(j.lastRun) < j.minInterval {
+		j.mu.Unlock()
+		zap.S().Debugf("任务[%s]执行过于频繁，跳过本次执行", j.name)
+		return
+	}
+	j.lastRun = time.Now()
+	j.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorf("任务[%s]执行异常: %v", j.name, r)
+			observeCompressionError("scheduler_panic")
+		}
+	}()
+
+	j.fn(ctx)
+}
+
+// Scheduler 基于 cron 表达式的多任务调度器，取代单一每日定时器
+type Scheduler struct {
+	cron *cron.Cron
+
+	mu   sync.Mutex
+	jobs map[string]*schedulerJob
+}
+
+// NewScheduler 创建一个支持秒级精度cron表达式的调度器
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		cron: cron.New(cron.WithSeconds()),
+		jobs: make(map[string]*schedulerJob),
+	}
+}
+
+// AddJob 注册一个按 cronExpr 触发的命名任务，opts 可覆盖其去抖间隔等行为
+func (s *Scheduler) AddJob(name, cronExpr string, fn func(ctx context.Context), opts ...JobOption) error {
+	job := &schedulerJob{
+		name:        name,
+		cronExpr:    cronExpr,
+		fn:          fn,
+		minInterval: minInterval,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	id, err := s.cron.AddFunc(cronExpr, func() { job.run(context.Background()) })
+	if err != nil {
+		return fmt.Errorf("注册任务[%s]失败: %w", name, err)
+	}
+	job.entryID = id
+
+	s.mu.Lock()
+	s.jobs[name] = job
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RunNow 立即触发一次指定任务，忽略其cron调度时间（仍受去抖间隔限制）
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("未知任务: %s", name)
+	}
+	go job.run(context.Background())
+	return nil
+}
+
+// Start 启动调度器
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop 停止调度器，不再触发新任务；本身不等待正在执行的任务，返回的 context 在这些任务结束后 Done
+func (s *Scheduler) Stop() context.Context {
+	return s.cron.Stop()
+}
+
+// defaultScheduler 由 StartLogCompression 创建，供 RunNow/DefaultScheduler 等运维场景调用
+var defaultScheduler *Scheduler
+
+// DefaultScheduler 返回 StartLogCompression 内部创建的调度器，可用于运维触发 RunNow
+func DefaultScheduler() *Scheduler {
+	return defaultScheduler
+}
@@ -0,0 +1,72 @@
+package zaplogmanager
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// 分片累加器：AggregateByWindow 在多核机器上并发聚合时，若所有 key 都竞争同一把 sync.RWMutex
+// 会成为瓶颈，这里按 fnv32(key)%N 把 key 空间切分到 N 个独立加锁的分片上
+
+const accumulatorShardCount = 32
+
+type accumulatorShard struct {
+	mu   sync.Mutex
+	data map[string]map[int64]int64
+}
+
+type shardedAccumulator struct {
+	shards [accumulatorShardCount]*accumulatorShard
+}
+
+func newShardedAccumulator() *shardedAccumulator {
+	sa := &shardedAccumulator{}
+	for i := range sa.shards {
+		sa.shards[i] = &accumulatorShard{data: make(map[string]map[int64]int64)}
+	}
+	return sa
+}
+
+// fnv32 计算 FNV-1a 哈希，用于将 key 映射到分片
+func fnv32(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func (sa *shardedAccumulator) shardFor(key string) *accumulatorShard {
+	return sa.shards[fnv32(key)%accumulatorShardCount]
+}
+
+func (sa *shardedAccumulator) add(key string, window int64, value int64) {
+	shard := sa.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	windows, ok := shard.data[key]
+	if !ok {
+		windows = make(map[int64]int64)
+		shard.data[key] = windows
+	}
+	windows[window] += value
+}
+
+// merge 汇总所有分片的数据，返回 key -> (窗口起始时间戳 -> 累计值)
+func (sa *shardedAccumulator) merge() map[string]map[int64]int64 {
+	result := make(map[string]map[int64]int64)
+	for _, shard := range sa.shards {
+		shard.mu.Lock()
+		for key, windows := range shard.data {
+			dst, ok := result[key]
+			if !ok {
+				dst = make(map[int64]int64)
+				result[key] = dst
+			}
+			for w, v := range windows {
+				dst[w] += v
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return result
+}
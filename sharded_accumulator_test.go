@@ -0,0 +1,54 @@
+package zaplogmanager
+
+import "testing"
+
+func TestShardedAccumulatorAddMerge(t *testing.T) {
+	sa := newShardedAccumulator()
+	sa.add("a", 1000, 1)
+	sa.add("a", 1000, 2)
+	sa.add("a", 2000, 5)
+	sa.add("b", 1000, 10)
+
+	merged := sa.merge()
+
+	if got := merged["a"][1000]; got != 3 {
+		t.Errorf("key=a window=1000 期望累计3，实际%d", got)
+	}
+	if got := merged["a"][2000]; got != 5 {
+		t.Errorf("key=a window=2000 期望累计5，实际%d", got)
+	}
+	if got := merged["b"][1000]; got != 10 {
+		t.Errorf("key=b window=1000 期望累计10，实际%d", got)
+	}
+}
+
+func TestShardedAccumulatorMergeIsolatesFromFurtherAdds(t *testing.T) {
+	sa := newShardedAccumulator()
+	sa.add("a", 1000, 1)
+
+	merged := sa.merge()
+	sa.add("a", 1000, 100)
+
+	if merged["a"][1000] != 1 {
+		t.Errorf("merge() 返回的快照不应被后续 add 影响，实际变为%d", merged["a"][1000])
+	}
+}
+
+func TestFnv32Deterministic(t *testing.T) {
+	if fnv32("same-key") != fnv32("same-key") {
+		t.Errorf("fnv32 对相同输入应返回相同哈希")
+	}
+	if fnv32("key-a") == fnv32("key-b") {
+		t.Skip("哈希恰好碰撞，跳过（极小概率，非失败信号）")
+	}
+}
+
+func TestShardForWithinRange(t *testing.T) {
+	sa := newShardedAccumulator()
+	for _, key := range []string{"", "x", "日志", "a-very-long-key-used-for-sharding-test"} {
+		shard := sa.shardFor(key)
+		if shard == nil {
+			t.Errorf("shardFor(%q) 不应返回 nil", key)
+		}
+	}
+}
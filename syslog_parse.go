@@ -0,0 +1,166 @@
+package zaplogmanager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslog 协议解析模块：支持 RFC3164（BSD syslog）与 RFC5424（结构化 syslog）两种报文格式
+
+// ParsedSyslog 是一条解析后的 syslog 消息
+type ParsedSyslog struct {
+	Priority  int // PRI = Facility*8 + Severity
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string // RFC3164 中对应 TAG
+	ProcID    string
+	MsgID     string
+	Message   string
+	Raw       string
+}
+
+// parseSyslogMessage 解析一条原始 syslog 报文（不含 RFC6587 的帧长度前缀）
+func parseSyslogMessage(raw string) (ParsedSyslog, error) {
+	raw = strings.TrimRight(raw, "\r\n")
+	if raw == "" {
+		return ParsedSyslog{}, fmt.Errorf("空的syslog报文")
+	}
+
+	pri, rest, err := parsePriority(raw)
+	if err != nil {
+		return ParsedSyslog{}, err
+	}
+
+	msg := ParsedSyslog{
+		Priority: pri,
+		Facility: pri / 8,
+		Severity: pri % 8,
+		Raw:      raw,
+	}
+
+	// RFC5424: "1 2003-10-11T22:14:15.003Z host app - msgid - msg"
+	if rest2, ok := strings.CutPrefix(rest, "1 "); ok {
+		return parseRFC5424(msg, rest2)
+	}
+
+	return parseRFC3164(msg, rest)
+}
+
+// parsePriority 解析 "<PRI>" 前缀，返回 PRI 值以及剩余部分
+func parsePriority(raw string) (int, string, error) {
+	if !strings.HasPrefix(raw, "<") {
+		return 0, "", fmt.Errorf("缺少PRI前缀: %s", raw)
+	}
+	end := strings.IndexByte(raw, '>')
+	if end < 0 {
+		return 0, "", fmt.Errorf("PRI前缀未闭合: %s", raw)
+	}
+	pri, err := strconv.Atoi(raw[1:end])
+	if err != nil {
+		return 0, "", fmt.Errorf("PRI不是数字: %w", err)
+	}
+	return pri, raw[end+1:], nil
+}
+
+// parseRFC5424 解析 VERSION 之后的部分：TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [STRUCTURED-DATA] MSG
+func parseRFC5424(msg ParsedSyslog, rest string) (ParsedSyslog, error) {
+	fields := strings.SplitN(rest, " ", 6)
+	if len(fields) < 6 {
+		return ParsedSyslog{}, fmt.Errorf("RFC5424报文字段不足: %s", rest)
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		ts = time.Now()
+	}
+	msg.Timestamp = ts
+	msg.Hostname = nilDashToEmpty(fields[1])
+	msg.AppName = nilDashToEmpty(fields[2])
+	msg.ProcID = nilDashToEmpty(fields[3])
+	msg.MsgID = nilDashToEmpty(fields[4])
+
+	// 跳过结构化数据（SD-ELEMENT 或 "-"），剩余即为正文
+	body := fields[5]
+	if strings.HasPrefix(body, "-") {
+		msg.Message = strings.TrimPrefix(body, "- ")
+	} else if strings.HasPrefix(body, "[") {
+		if idx := strings.Index(body, "] "); idx >= 0 {
+			msg.Message = body[idx+2:]
+		} else {
+			msg.Message = ""
+		}
+	} else {
+		msg.Message = body
+	}
+
+	return msg, nil
+}
+
+// parseRFC3164 解析传统 BSD syslog 格式："Jan _2 15:04:05 HOSTNAME TAG: MSG"
+func parseRFC3164(msg ParsedSyslog, rest string) (ParsedSyslog, error) {
+	if len(rest) < 15 {
+		return ParsedSyslog{}, fmt.Errorf("RFC3164报文过短: %s", rest)
+	}
+
+	timestampPart := rest[:15]
+	ts, err := time.Parse("Jan _2 15:04:05", timestampPart)
+	if err != nil {
+		ts = time.Now()
+	} else {
+		now := time.Now()
+		ts = time.Date(now.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, now.Location())
+	}
+	msg.Timestamp = ts
+
+	remainder := strings.TrimPrefix(rest[15:], " ")
+	fields := strings.SplitN(remainder, " ", 2)
+	msg.Hostname = fields[0]
+	if len(fields) < 2 {
+		msg.Message = ""
+		return msg, nil
+	}
+
+	tagAndMsg := fields[1]
+	if idx := strings.Index(tagAndMsg, ": "); idx >= 0 {
+		msg.AppName = stripPidSuffix(tagAndMsg[:idx])
+		msg.Message = tagAndMsg[idx+2:]
+	} else {
+		msg.Message = tagAndMsg
+	}
+
+	return msg, nil
+}
+
+// stripPidSuffix 去掉 TAG 末尾的 "[pid]" 后缀（如 "nginx[123]" -> "nginx"）。
+// 不能用 strings.TrimRight(s, "[0123456789]") 按字符集裁剪——那会把本身以数字结尾、
+// 没有 pid 的合法 TAG（如 "nginx1"）也一并截断，这里要求方括号内必须是非空纯数字才裁剪
+func stripPidSuffix(tag string) string {
+	if !strings.HasSuffix(tag, "]") {
+		return tag
+	}
+	open := strings.LastIndexByte(tag, '[')
+	if open < 0 {
+		return tag
+	}
+	pid := tag[open+1 : len(tag)-1]
+	if pid == "" {
+		return tag
+	}
+	for _, r := range pid {
+		if r < '0' || r > '9' {
+			return tag
+		}
+	}
+	return tag[:open]
+}
+
+func nilDashToEmpty(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package zaplogmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSyslogMessageRFC5424(t *testing.T) {
+	raw := "<165>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick"
+	msg, err := parseSyslogMessage(raw)
+	if err != nil {
+		t.Fatalf("解析RFC5424报文失败: %v", err)
+	}
+
+	if msg.Priority != 165 || msg.Facility != 20 || msg.Severity != 5 {
+		t.Errorf("PRI解析错误: priority=%d facility=%d severity=%d", msg.Priority, msg.Facility, msg.Severity)
+	}
+	if msg.Hostname != "mymachine.example.com" {
+		t.Errorf("hostname解析错误: %q", msg.Hostname)
+	}
+	if msg.AppName != "su" {
+		t.Errorf("appname解析错误: %q", msg.AppName)
+	}
+	if msg.MsgID != "ID47" {
+		t.Errorf("msgid解析错误: %q", msg.MsgID)
+	}
+	if msg.Message != "'su root' failed for lonvick" {
+		t.Errorf("message解析错误: %q", msg.Message)
+	}
+
+	wantTs := time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC)
+	if !msg.Timestamp.Equal(wantTs) {
+		t.Errorf("timestamp解析错误: got %v, want %v", msg.Timestamp, wantTs)
+	}
+}
+
+func TestParseSyslogMessageRFC5424NilFields(t *testing.T) {
+	raw := "<13>1 2023-01-01T00:00:00Z - - - - hello world"
+	msg, err := parseSyslogMessage(raw)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if msg.Hostname != "" || msg.AppName != "" || msg.ProcID != "" || msg.MsgID != "" {
+		t.Errorf("NILVALUE(-)字段应当被归一化为空字符串，实际: %+v", msg)
+	}
+	if msg.Message != "hello world" {
+		t.Errorf("message解析错误: %q", msg.Message)
+	}
+}
+
+func TestParseSyslogMessageRFC3164(t *testing.T) {
+	raw := "<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8"
+	msg, err := parseSyslogMessage(raw)
+	if err != nil {
+		t.Fatalf("解析RFC3164报文失败: %v", err)
+	}
+
+	if msg.Priority != 34 || msg.Facility != 4 || msg.Severity != 2 {
+		t.Errorf("PRI解析错误: priority=%d facility=%d severity=%d", msg.Priority, msg.Facility, msg.Severity)
+	}
+	if msg.Hostname != "mymachine" {
+		t.Errorf("hostname解析错误: %q", msg.Hostname)
+	}
+	if msg.AppName != "su" {
+		t.Errorf("tag解析错误: %q", msg.AppName)
+	}
+	if msg.Message != "'su root' failed for lonvick on /dev/pts/8" {
+		t.Errorf("message解析错误: %q", msg.Message)
+	}
+}
+
+func TestParseSyslogMessageRFC3164TagWithPidEndingInDigit(t *testing.T) {
+	raw := "<34>Oct 11 22:14:15 mymachine nginx1[123]: worker started"
+	msg, err := parseSyslogMessage(raw)
+	if err != nil {
+		t.Fatalf("解析RFC3164报文失败: %v", err)
+	}
+	if msg.AppName != "nginx1" {
+		t.Errorf("tag末位是数字时不应被当作pid一并裁掉: got %q, want %q", msg.AppName, "nginx1")
+	}
+	if msg.Message != "worker started" {
+		t.Errorf("message解析错误: %q", msg.Message)
+	}
+}
+
+func TestParseSyslogMessageInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"no priority prefix here",
+		"<12 missing closing bracket",
+		"<abc>not a number",
+	}
+	for _, raw := range cases {
+		if _, err := parseSyslogMessage(raw); err == nil {
+			t.Errorf("期望解析失败，但成功了: %q", raw)
+		}
+	}
+}
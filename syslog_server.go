@@ -0,0 +1,318 @@
+package zaplogmanager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// syslog 接入模块：监听 UDP/TCP 接收 RFC3164/RFC5424 报文（TCP 侧支持 RFC6587 八位组计数分帧），
+// 按 RouteFunc 分流写入受管的 logDirs 目录树，文件滚动复用既有的 gzipLogFileWithIndex 压缩实现，
+// 使得落盘后的跨天压缩/过期清理/保留策略无需任何改动即可生效
+
+const defaultSyslogRolloverBytes = int64(6 << 20) // 6MiB，参考常见 syslog-server 实现的默认滚动阈值
+
+// RouteFunc 根据解析后的消息决定写入哪个目录、哪个文件名，便于按 facility/severity/hostname/app-name 自定义分区
+type RouteFunc func(msg ParsedSyslog) (dir, filename string)
+
+// WithSyslogRoute 设置自定义的消息路由函数，不设置时使用 WithSyslogBaseDir 指定目录下的按主机名分区
+func WithSyslogRoute(route RouteFunc) Option {
+	return func(o *loggerOptions) { o.syslogRoute = route }
+}
+
+// WithSyslogBaseDir 设置默认路由函数使用的根目录（需配合 StartSyslogServer 使用，未设置自定义 RouteFunc 时生效）
+func WithSyslogBaseDir(dir string) Option {
+	return func(o *loggerOptions) { o.syslogBaseDir = dir }
+}
+
+// WithSyslogRollover 覆盖默认的6MiB单文件滚动阈值
+func WithSyslogRollover(bytes int64) Option {
+	return func(o *loggerOptions) { o.syslogRolloverBytes = bytes }
+}
+
+// SyslogServer 是启动后的 syslog 接收服务句柄
+type SyslogServer struct {
+	route         RouteFunc
+	rolloverBytes int64
+
+	udpConn     net.PacketConn
+	tcpListener net.Listener
+
+	writers sync.Map // path(string) -> *syslogFileWriter
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartSyslogServer 启动一个 syslog 接收服务，proto 取值 "udp" 或 "tcp"
+func StartSyslogServer(addr, proto string, opts ...Option) (*SyslogServer, error) {
+	o := defaultLoggerOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	route := o.syslogRoute
+	if route == nil {
+		route = defaultSyslogRoute(o.syslogBaseDir)
+	}
+	rollover := o.syslogRolloverBytes
+	if rollover <= 0 {
+		rollover = defaultSyslogRolloverBytes
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &SyslogServer{route: route, rolloverBytes: rollover, cancel: cancel}
+
+	switch strings.ToLower(proto) {
+	case "udp":
+		conn, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("监听UDP失败: %w", err)
+		}
+		s.udpConn = conn
+		s.wg.Add(1)
+		go s.serveUDP(ctx)
+	case "tcp":
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("监听TCP失败: %w", err)
+		}
+		s.tcpListener = ln
+		s.wg.Add(1)
+		go s.serveTCP(ctx)
+	default:
+		cancel()
+		return nil, fmt.Errorf("不支持的协议: %s（仅支持 udp/tcp）", proto)
+	}
+
+	return s, nil
+}
+
+// Shutdown 优雅关闭监听与所有正在处理的连接
+func (s *SyslogServer) Shutdown(ctx context.Context) error {
+	s.cancel()
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SyslogServer) serveUDP(ctx context.Context) {
+	defer s.wg.Done()
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := s.udpConn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				zap.S().Debugf("syslog UDP读取结束: %v", err)
+				return
+			}
+		}
+		s.handleLine(string(buf[:n]))
+	}
+}
+
+func (s *SyslogServer) serveTCP(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		conn, err := s.tcpListener.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				zap.S().Debugf("syslog TCP监听结束: %v", err)
+				return
+			}
+		}
+
+		s.wg.Add(1)
+		go s.handleTCPConn(ctx, conn)
+	}
+}
+
+func (s *SyslogServer) handleTCPConn(ctx context.Context, conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	// Shutdown 取消 ctx 时主动关闭连接，使阻塞在读取上的 readSyslogFrame 立即返回
+	connDone := make(chan struct{})
+	defer close(connDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-connDone:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		line, err := readSyslogFrame(reader)
+		if err != nil {
+			if err != io.EOF {
+				zap.S().Debugf("syslog TCP连接读取结束: %v", err)
+			}
+			return
+		}
+		s.handleLine(line)
+	}
+}
+
+// readSyslogFrame 按 RFC6587 读取一帧：八位组计数（"LEN MSG"）或回退到以换行分隔的非透明帧
+func readSyslogFrame(r *bufio.Reader) (string, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if b[0] >= '0' && b[0] <= '9' {
+		lengthStr, err := r.ReadString(' ')
+		if err != nil {
+			return "", err
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(lengthStr, " "))
+		if err != nil {
+			return "", fmt.Errorf("无效的RFC6587帧长度: %s", lengthStr)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return "", err
+		}
+		return string(frame), nil
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return line, nil
+}
+
+func (s *SyslogServer) handleLine(raw string) {
+	msg, err := parseSyslogMessage(raw)
+	if err != nil {
+		zap.S().Debugf("syslog报文解析失败: %v, 原始报文: %s", err, raw)
+		return
+	}
+
+	dir, filename := s.route(msg)
+	if err := s.writeMessage(dir, filename, msg); err != nil {
+		zap.S().Errorf("syslog写入失败: %v", err)
+	}
+}
+
+func (s *SyslogServer) writeMessage(dir, filename string, msg ParsedSyslog) error {
+	path := filepath.Join(dir, filename)
+
+	actual, _ := s.writers.LoadOrStore(path, &syslogFileWriter{path: path})
+	writer := actual.(*syslogFileWriter)
+	return writer.append(msg.Raw, s.rolloverBytes)
+}
+
+// defaultSyslogRoute 未配置 RouteFunc 时的默认分区：baseDir/hostname/20060102.log
+func defaultSyslogRoute(baseDir string) RouteFunc {
+	return func(msg ParsedSyslog) (string, string) {
+		host := msg.Hostname
+		if host == "" {
+			host = "unknown"
+		}
+		return filepath.Join(baseDir, host), fmt.Sprintf("%s.log", time.Now().Format(dateFormat))
+	}
+}
+
+// syslogFileWriter 单文件写入器。append 在持有 mu 期间完成"写入 + 大小判断 + 轮转重命名"，
+// 保证滚动只在阈值刚被跨越的那一次触发一次，而不是此后每一行都重新判断同一个仍在增长的文件
+type syslogFileWriter struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (w *syslogFileWriter) append(line string, rolloverBytes int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return fmt.Errorf("创建syslog日志目录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开syslog日志文件失败: %w", err)
+	}
+
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("写入syslog日志失败: %w", err)
+	}
+
+	fi, statErr := f.Stat()
+	if closeErr := f.Close(); closeErr != nil {
+		return fmt.Errorf("关闭syslog日志文件失败: %w", closeErr)
+	}
+
+	if statErr == nil && fi.Size() >= rolloverBytes {
+		w.rotate()
+	}
+
+	return nil
+}
+
+// rotate 把当前文件重命名为带时间戳的备份（此后 w.path 重新从零开始写入），
+// 再异步压缩该备份——复用 forceCompressOvernightLog 同款的 gzipLogFileWithIndex+Remove 组合，不重新实现轮转
+func (w *syslogFileWriter) rotate() {
+	backup := fmt.Sprintf("%s.%s", w.path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(w.path, backup); err != nil {
+		if !os.IsNotExist(err) {
+			zap.S().Errorf("syslog日志滚动重命名失败: %v", err)
+		}
+		return
+	}
+
+	go func() {
+		compressedName := backup + CurrentCompressor().Extension()
+		if err := gzipLogFileWithIndex(backup, compressedName); err != nil {
+			zap.S().Errorf("syslog日志滚动压缩失败: %v", err)
+			return
+		}
+		if err := os.Remove(backup); err != nil {
+			zap.S().Errorf("删除syslog滚动原文件失败: %v", err)
+		}
+	}()
+}
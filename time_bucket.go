@@ -0,0 +1,246 @@
+package zaplogmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 时间分桶目录布局模块：每个轮转窗口（如每分钟）创建独立的时间戳子目录，
+// 桶内再按大小切分并即时压缩，形成 logs/info/202401151530/202401151530-info-0.log.gz ...
+// 这种布局便于对冷数据按整个时间窗口做保留清理
+
+// bucketDirRegex 匹配分桶目录名：精确到分钟的时间戳，如 202401151530
+var bucketDirRegex = regexp.MustCompile(`^(20\d{2})(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])([01][0-9]|2[0-3])([0-5][0-9])$`)
+
+const bucketTimeLayout = "200601021504"
+
+// bucketTimeFromDirName 尝试把目录名解析为分桶时间戳
+func bucketTimeFromDirName(name string) (time.Time, bool) {
+	if !bucketDirRegex.MatchString(name) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(bucketTimeLayout, name, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// BucketPolicy 时间分桶+桶内大小切分策略
+// BucketInterval: 分桶窗口长度，建议设置为 time.Minute 或其倍数
+// MaxSegmentSizeMB: 桶内单个分段达到该大小后切分为新分段，0 表示桶内不按大小切分
+type BucketPolicy struct {
+	BucketInterval   time.Duration
+	MaxSegmentSizeMB int
+}
+
+// bucketWriter 按时间分桶目录+桶内大小切分写入日志
+type bucketWriter struct {
+	mu          sync.Mutex
+	rootDir     string
+	level       string
+	policy      BucketPolicy
+	bucketName  string
+	bucketStart time.Time
+	segIndex    int
+	file        *os.File
+	size        int64
+}
+
+// NewBucketWriter 创建一个时间分桶写入器，rootDir 为该级别日志的根目录（如 logs/info）
+func NewBucketWriter(rootDir, level string, policy BucketPolicy) (zapcore.WriteSyncer, error) {
+	if policy.BucketInterval <= 0 {
+		policy.BucketInterval = time.Minute
+	} else if policy.BucketInterval < time.Minute {
+		// bucketTimeLayout 精确到分钟，分桶目录名/分段序号都以"分钟"为最小粒度；
+		// 允许亚分钟间隔会导致同一分钟内的两次轮转复用同一个目录名和同一个分段序号0，
+		// 第二次 rotateBucket 的 os.Rename 会直接覆盖第一次的分段文件。这里直接提升到1分钟下限
+		zap.S().Warnf("BucketInterval=%v 小于分桶目录的最小粒度1分钟，已提升为1分钟", policy.BucketInterval)
+		policy.BucketInterval = time.Minute
+	}
+	bw := &bucketWriter{rootDir: rootDir, level: level, policy: policy}
+	if err := bw.openBucket(time.Now()); err != nil {
+		return nil, err
+	}
+	return bw, nil
+}
+
+func (bw *bucketWriter) bucketDir() string {
+	return filepath.Join(bw.rootDir, bw.bucketName)
+}
+
+func (bw *bucketWriter) currentPath() string {
+	return filepath.Join(bw.bucketDir(), fmt.Sprintf("%s-%s.log", bw.bucketName, bw.level))
+}
+
+// openBucket 创建分桶目录并打开当前（未切分）的日志文件
+func (bw *bucketWriter) openBucket(at time.Time) error {
+	bw.bucketName = at.Format(bucketTimeLayout)
+	bw.bucketStart = at
+	bw.segIndex = 0
+
+	if err := os.MkdirAll(bw.bucketDir(), 0755); err != nil {
+		return fmt.Errorf("创建分桶目录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(bw.currentPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开分桶当前日志文件失败: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("读取分桶日志状态失败: %w", err)
+	}
+
+	bw.file = f
+	bw.size = fi.Size()
+	return nil
+}
+
+// Write 实现 io.Writer，按时间窗口或桶内大小阈值触发切分
+func (bw *bucketWriter) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if time.Since(bw.bucketStart) >= bw.policy.BucketInterval {
+		if err := bw.rotateBucket(); err != nil {
+			zap.S().Errorf("分桶轮转失败: %v", err)
+		}
+	} else if bw.policy.MaxSegmentSizeMB > 0 && bw.size+int64(len(p)) > int64(bw.policy.MaxSegmentSizeMB)*1024*1024 {
+		if err := bw.rotateSegment(); err != nil {
+			zap.S().Errorf("桶内分段切分失败: %v", err)
+		}
+	}
+
+	n, err := bw.file.Write(p)
+	bw.size += int64(n)
+	return n, err
+}
+
+// Sync 实现 zapcore.WriteSyncer
+func (bw *bucketWriter) Sync() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.file.Sync()
+}
+
+// rotateSegment 关闭桶内当前分段，以递增序号重命名并用当前激活的 Compressor 后台压缩，随后打开新的当前分段
+func (bw *bucketWriter) rotateSegment() error {
+	if err := bw.closeAndCompressSegment(); err != nil {
+		return err
+	}
+	bw.segIndex++
+	return bw.reopenCurrent()
+}
+
+// rotateBucket 收尾当前分桶（压缩末段），切换到新的时间分桶目录
+func (bw *bucketWriter) rotateBucket() error {
+	if err := bw.closeAndCompressSegment(); err != nil {
+		return err
+	}
+	return bw.openBucket(time.Now())
+}
+
+func (bw *bucketWriter) closeAndCompressSegment() error {
+	if err := bw.file.Close(); err != nil {
+		return fmt.Errorf("关闭分段文件失败: %w", err)
+	}
+
+	segName := filepath.Join(bw.bucketDir(), fmt.Sprintf("%s-%s-%d.log", bw.bucketName, bw.level, bw.segIndex))
+	if err := os.Rename(bw.currentPath(), segName); err != nil {
+		return fmt.Errorf("重命名分段文件失败: %w", err)
+	}
+
+	go compressBackupInBackground(segName)
+	return nil
+}
+
+func (bw *bucketWriter) reopenCurrent() error {
+	f, err := os.OpenFile(bw.currentPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开新分段文件失败: %w", err)
+	}
+	bw.file = f
+	bw.size = 0
+	return nil
+}
+
+// WithWarnBucketPolicy 让 warn 级别改用时间分桶目录布局（NewBucketWriter），取代默认的 NewRotationWriter
+func WithWarnBucketPolicy(policy BucketPolicy) Option {
+	return func(o *loggerOptions) { o.warnBucket = &policy }
+}
+
+// WithInfoBucketPolicy 让 info 级别改用时间分桶目录布局
+func WithInfoBucketPolicy(policy BucketPolicy) Option {
+	return func(o *loggerOptions) { o.infoBucket = &policy }
+}
+
+// WithDebugBucketPolicy 让 debug 级别改用时间分桶目录布局
+func WithDebugBucketPolicy(policy BucketPolicy) Option {
+	return func(o *loggerOptions) { o.debugBucket = &policy }
+}
+
+// cleanExpiredBucketDirs 清理整批过期的时间分桶目录：仅当桶内所有文件都已超过 maxSaveTime 才整目录删除
+func cleanExpiredBucketDirs(logDir string, maxSaveTime time.Duration) error {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取日志目录失败: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxSaveTime)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bucketTime, ok := bucketTimeFromDirName(entry.Name())
+		if !ok || !bucketTime.Before(cutoff) {
+			continue
+		}
+
+		dirPath := filepath.Join(logDir, entry.Name())
+		if allFilesExpired(dirPath, cutoff) {
+			if err := os.RemoveAll(dirPath); err != nil {
+				zap.S().Errorf("删除过期分桶目录失败: %s -> %v", dirPath, err)
+				continue
+			}
+			zap.S().Infof("已删除过期分桶目录: %s", dirPath)
+		}
+	}
+	return nil
+}
+
+// allFilesExpired 判断分桶目录内每个文件是否都已过期（按当前正在写入的文件则视为未过期）
+func allFilesExpired(dirPath string, cutoff time.Time) bool {
+	files, err := os.ReadDir(dirPath)
+	if err != nil {
+		return false
+	}
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(dirPath, f.Name())
+		if currentLogRegex.MatchString(path) {
+			// 仍有未压缩的 .log 文件（当前正在写入的文件，或尚未压缩完成的带序号分段），不应被清理
+			return false
+		}
+		fi, err := f.Info()
+		if err != nil || fi.ModTime().After(cutoff) {
+			return false
+		}
+	}
+	return true
+}